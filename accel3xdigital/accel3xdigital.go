@@ -190,7 +190,7 @@ type State struct {
 
 // Acceleration returns the acceleration (g) for each axis (x,y,z)
 func (s *State) Acceleration() (float64, float64, float64) {
-	return s.X / 21.0, s.Y / 21.0, s.Y / 21.0
+	return s.X / 21.0, s.Y / 21.0, s.Z / 21.0
 }
 
 // String implements the stringer interface
@@ -0,0 +1,77 @@
+package accel3xdigital
+
+import (
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func openAccel(t *testing.T) (*Accel3xDigital, *devicetest.I2C) {
+	bus := &devicetest.I2C{}
+	a, err := Open(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, bus
+}
+
+func TestOpen(t *testing.T) {
+	a, bus := openAccel(t)
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "open")
+}
+
+func TestEnableTap(t *testing.T) {
+	a, bus := openAccel(t)
+	bus.Reset()
+	if err := a.SetTapSensitivity(40); err != nil {
+		t.Fatal(err)
+	}
+	if !a.TapEnabled {
+		t.Error("tap detection should be enabled")
+	}
+	bus.CheckGolden(t, "tap")
+}
+
+func TestUpdate(t *testing.T) {
+	a, bus := openAccel(t)
+	// X = 5, Y = -2, Z = 21, lying on its front, up and tapped.
+	bus.Reply(addr, []byte{0x05, 0x3e, 0x15, 0x39})
+	if err := a.Update(); err != nil {
+		t.Fatal(err)
+	}
+	s := a.State
+	if s.X != 5 || s.Y != -2 || s.Z != 21 {
+		t.Errorf("got x=%v, y=%v, z=%v; want x=5, y=-2, z=21", s.X, s.Y, s.Z)
+	}
+	if !s.Front || s.Back {
+		t.Errorf("got front=%v, back=%v; want front=true, back=false", s.Front, s.Back)
+	}
+	if s.Position != Up {
+		t.Errorf("got position %v, want %v", s.Position, Up)
+	}
+	if !s.Tapped || s.Shaken {
+		t.Errorf("got tapped=%v, shaken=%v; want tapped=true, shaken=false", s.Tapped, s.Shaken)
+	}
+	if _, _, z := s.Acceleration(); z != 1 {
+		t.Errorf("got z acceleration %vg, want 1g", z)
+	}
+}
+
+func TestUpdateNotReady(t *testing.T) {
+	a, bus := openAccel(t)
+	bus.Reply(addr, []byte{0x40, 0x00, 0x00, 0x00})
+	if err := a.Update(); err != ErrNotReady {
+		t.Errorf("got = %v, want = %v", err, ErrNotReady)
+	}
+}
+
+func TestUpdateAlert(t *testing.T) {
+	a, bus := openAccel(t)
+	bus.Reply(addr, []byte{0x00, 0x00, 0x00, 0x40})
+	if err := a.Update(); err == nil {
+		t.Error("an alert should be reported as an error")
+	}
+}
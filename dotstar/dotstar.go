@@ -2,16 +2,27 @@
 package dotstar
 
 import (
+	"github.com/goiot/devices/internal/ledstrip"
 	"golang.org/x/exp/io/spi"
 	"golang.org/x/exp/io/spi/driver"
 )
 
+const (
+	// DefaultMaxSpeed is the SPI clock speed (in Hz) set by Open.
+	DefaultMaxSpeed = 4000000
+
+	// DefaultMaxTxSize is the default maximum number of bytes sent in
+	// a single SPI transaction. It matches the default buffer size of
+	// the Linux spidev driver.
+	DefaultMaxTxSize = 4096
+)
+
 // RGBA represents the color of a dostar LED.
 type RGBA struct {
 	R byte // R represents the red intensity.
 	G byte // G represents the green intensity.
 	B byte // B represents the blue intensity.
-	A byte // A is the brightness of the LED. Must be between 0 and 15.
+	A byte // A is the brightness of the LED. Must be between 0 and 31.
 }
 
 // LEDs represent a strip of dotstar LEDs.
@@ -20,10 +31,17 @@ type LEDs struct {
 	// LED strip. Most users don't have to access this field.
 	Device *spi.Device
 
+	// MaxTxSize is the maximum number of bytes sent in a single SPI
+	// transaction. Draw splits longer frames into several transactions.
+	// Zero means no limit.
+	MaxTxSize int
+
 	vals []RGBA
+	tx   []byte // frame buffer reused by Draw
 }
 
-// Open opens a new LED strip with n dotstar LEDs. An LED strip
+// Open opens a new LED strip with n dotstar LEDs. The SPI clock is set
+// to DefaultMaxSpeed, use SetMaxSpeed to change it. An LED strip
 // must be closed if no longer in use.
 func Open(o driver.Opener, n int) (*LEDs, error) {
 	dev, err := spi.Open(o)
@@ -41,12 +59,30 @@ func Open(o driver.Opener, n int) (*LEDs, error) {
 		return nil, err
 	}
 
+	if err := dev.SetMaxSpeed(DefaultMaxSpeed); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
 	return &LEDs{
-		Device: dev,
-		vals:   make([]RGBA, n),
+		Device:    dev,
+		MaxTxSize: DefaultMaxTxSize,
+		vals:      make([]RGBA, n),
 	}, nil
 }
 
+// SetMaxSpeed sets the SPI clock speed in Hz. APA102 strips support
+// clocks well above 10MHz but long cables or level shifters may require
+// lower speeds.
+func (d *LEDs) SetMaxSpeed(hz int) error {
+	return d.Device.SetMaxSpeed(hz)
+}
+
+// Len returns the number of LEDs on the strip.
+func (d *LEDs) Len() int {
+	return len(d.vals)
+}
+
 // SetRGBA sets the ith LED's color to the given RGBA value.
 // A call to Draw is required to transmit the new value
 // to the LED strip.
@@ -55,10 +91,15 @@ func (d *LEDs) SetRGBA(i int, v RGBA) {
 }
 
 // Draw displays the RGBA values set on the actual LED strip.
+// Frames longer than MaxTxSize are sent in several transactions.
 func (d *LEDs) Draw() error {
 	// TODO(jbd): dotstar allows other RGBA allignments, support those layouts.
 	n := len(d.vals)
-	tx := make([]byte, 4*(n+1)+(n/2+1))
+	size := 4*(n+1) + (n/2 + 1)
+	if len(d.tx) != size {
+		d.tx = make([]byte, size)
+	}
+	tx := d.tx
 	tx[0] = 0x00
 	tx[1] = 0x00
 	tx[2] = 0x00
@@ -66,7 +107,7 @@ func (d *LEDs) Draw() error {
 
 	for i, c := range d.vals {
 		j := (i + 1) * 4
-		tx[j] = 0xe0 + c.A
+		tx[j] = 0xe0 | c.A&0x1f // the 3 high bits start the LED frame
 		tx[j+1] = c.B
 		tx[j+2] = c.G
 		tx[j+3] = c.R
@@ -77,7 +118,8 @@ func (d *LEDs) Draw() error {
 		tx[i] = 0xff
 	}
 
-	return d.Device.Tx(tx, nil)
+	// APA102 LEDs latch on the clock, pausing between chunks is harmless.
+	return ledstrip.Transmit(d.Device, tx, d.MaxTxSize)
 }
 
 // Close frees the underlying resources. It must be called once
@@ -0,0 +1,144 @@
+package dotstar
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+	"golang.org/x/exp/io/spi/driver"
+)
+
+func TestOpenConfigure(t *testing.T) {
+	bus := &devicetest.SPI{}
+	if _, err := Open(bus, 1); err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]int{
+		driver.Mode:     3,
+		driver.Bits:     8,
+		driver.MaxSpeed: DefaultMaxSpeed,
+	}
+	for k, v := range want {
+		if got, _ := bus.Config(k); got != v {
+			t.Errorf("config[%d] = %d, want = %d", k, got, v)
+		}
+	}
+}
+
+func TestSetMaxSpeed(t *testing.T) {
+	d, bus := openLEDs(t, 1)
+	if err := d.SetMaxSpeed(12000000); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := bus.Config(driver.MaxSpeed); got != 12000000 {
+		t.Errorf("max speed = %d, want = %d", got, 12000000)
+	}
+}
+
+func TestDrawChunks(t *testing.T) {
+	bus := &devicetest.SPI{MaxTxSize: 16}
+	d, err := Open(bus, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.MaxTxSize = 16
+	for i := 0; i < 6; i++ {
+		d.SetRGBA(i, RGBA{R: byte(i), G: 0x10, B: 0x20, A: 31})
+	}
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 4 bytes of start frame, 6*4 bytes of LEDs and 4 bytes of end frame
+	// are sent in two chunks.
+	chunks := bus.Writes()
+	if len(chunks) != 2 {
+		t.Fatalf("got %d transactions, want = 2", len(chunks))
+	}
+	for i, c := range chunks {
+		if len(c) != 16 {
+			t.Errorf("chunk %d is %d bytes long, want = 16", i, len(c))
+		}
+	}
+	frame := bus.Written()
+
+	want := []byte{
+		0, 0, 0, 0,
+		0xff, 0x20, 0x10, 0,
+		0xff, 0x20, 0x10, 1,
+		0xff, 0x20, 0x10, 2,
+		0xff, 0x20, 0x10, 3,
+		0xff, 0x20, 0x10, 4,
+		0xff, 0x20, 0x10, 5,
+		0xff, 0xff, 0xff, 0xff,
+	}
+	if !bytes.Equal(frame, want) {
+		t.Errorf("got = %v, want = %v", frame, want)
+	}
+}
+
+func TestDrawBrightnessOverflow(t *testing.T) {
+	d, bus := openLEDs(t, 1)
+	d.SetRGBA(0, RGBA{R: 1, G: 2, B: 3, A: 0x25})
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	// Only the 5 low bits of A are sent, the LED frame start is kept.
+	want := []byte{0, 0, 0, 0, 0xe5, 3, 2, 1, 0xff}
+	if got := bus.Written(); !bytes.Equal(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestDrawLargeStrip(t *testing.T) {
+	const n = 2000
+	bus := &devicetest.SPI{MaxTxSize: DefaultMaxTxSize}
+	d, err := Open(bus, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		d.SetRGBA(i, RGBA{R: 255, A: 31})
+	}
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if size, want := len(bus.Written()), 4*(n+1)+n/2+1; size != want {
+		t.Errorf("sent %d bytes, want = %d", size, want)
+	}
+}
+
+func TestDrawUnlimited(t *testing.T) {
+	d, bus := openLEDs(t, 10)
+	d.MaxTxSize = 0
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(bus.Writes()); got != 1 {
+		t.Errorf("got %d transactions, want = 1", got)
+	}
+}
+
+func TestDrawGolden(t *testing.T) {
+	d, bus := openLEDs(t, 3)
+	d.SetRGBA(0, RGBA{R: 255, A: 31})
+	d.SetRGBA(1, RGBA{G: 255, A: 16})
+	d.SetRGBA(2, RGBA{B: 255, A: 1})
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "draw")
+}
+
+func TestOpenError(t *testing.T) {
+	bus := &devicetest.SPI{}
+	bus.FailOpen(nil)
+	if _, err := Open(bus, 1); err != devicetest.ErrInjected {
+		t.Errorf("got = %v, want = %v", err, devicetest.ErrInjected)
+	}
+}
+
+var _ Strip = (*LEDs)(nil)
@@ -0,0 +1,27 @@
+// Package main contains a program that displays the pixels sent by
+// Open Pixel Control clients on a dotstar LED strip.
+package main
+
+import (
+	"github.com/goiot/devices/dotstar"
+	"golang.org/x/exp/io/spi"
+)
+
+// n is the number of LEDs on the strip.
+const n = 64
+
+func main() {
+	d, err := dotstar.Open(&spi.Devfs{Dev: "/dev/spidev0.0", Mode: spi.Mode3}, n)
+	if err != nil {
+		panic(err)
+	}
+	defer d.Close()
+
+	s := dotstar.NewOPCServer()
+	if err := s.Handle(1, d); err != nil {
+		panic(err)
+	}
+	if err := s.ListenAndServe(""); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,31 @@
+package dotstar
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func openLEDs(t *testing.T, n int) (*LEDs, *devicetest.SPI) {
+	bus := &devicetest.SPI{}
+	d, err := Open(bus, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, bus
+}
+
+// waitWrites waits until n frames were written on the bus and returns
+// them.
+func waitWrites(t *testing.T, bus *devicetest.SPI, n int) [][]byte {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if w := bus.Writes(); len(w) >= n {
+			return w
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d frames to be drawn", n)
+	return nil
+}
@@ -0,0 +1,278 @@
+package dotstar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"sync"
+)
+
+// Open Pixel Control commands and the Fadecandy system exclusive
+// messages, see http://openpixelcontrol.org/ and
+// https://github.com/scanlime/fadecandy/blob/master/doc/fc_protocol_opc.md.
+const (
+	opcSetPixelColors  = 0x00
+	opcSystemExclusive = 0xff
+
+	opcBroadcast = 0x00 // channel 0 addresses every channel
+
+	fcSystemID                 = 0x0001
+	fcSetGlobalColorCorrection = 0x0001
+	fcSetFirmwareConfiguration = 0x0002
+)
+
+// OPCPort is the default TCP port of Open Pixel Control servers.
+const OPCPort = 7890
+
+// ColorCorrection is the Fadecandy global color correction. It is applied
+// to every pixel received by an OPCServer before it is sent to the LEDs.
+// For each color channel, inputs below LinearCutoff are scaled by
+// LinearSlope, the others are raised to the power of Gamma and then
+// scaled by the matching WhitePoint component. All values are in the 0..1
+// range except for Gamma.
+type ColorCorrection struct {
+	Gamma        float64    `json:"gamma"`
+	WhitePoint   [3]float64 `json:"whitepoint"`
+	LinearSlope  float64    `json:"linearSlope"`
+	LinearCutoff float64    `json:"linearCutoff"`
+}
+
+// DefaultColorCorrection leaves the received colors untouched.
+var DefaultColorCorrection = ColorCorrection{
+	Gamma:       1,
+	WhitePoint:  [3]float64{1, 1, 1},
+	LinearSlope: 1,
+}
+
+// OPCServer is an Open Pixel Control server that displays the pixels
+// sent by OPC clients on one or more LED strips.
+//
+// Besides the set pixel colors command, the server understands the
+// Fadecandy set global color correction message. Fadecandy firmware
+// configuration messages are accepted and ignored since dithering and
+// keyframe interpolation have no equivalent on SPI strips.
+type OPCServer struct {
+	mu         sync.Mutex
+	channels   map[byte][]Strip
+	lut        [3][256]byte
+	brightness byte // used for every pixel, OPC colors have no alpha
+	ln         net.Listener
+	conns      map[net.Conn]struct{}
+	closed     bool
+}
+
+// NewOPCServer returns an OPC server with no channels, full brightness
+// and the default color correction. Use Handle to map channels to LED
+// strips before serving.
+func NewOPCServer() *OPCServer {
+	s := &OPCServer{
+		brightness: 31,
+		channels:   make(map[byte][]Strip),
+		conns:      make(map[net.Conn]struct{}),
+	}
+	s.SetColorCorrection(DefaultColorCorrection)
+	return s
+}
+
+// Handle maps the OPC channel to the given strips. Pixels sent to the
+// channel are laid out on the strips in order: the first pixels go to
+// the first strip and once it is full, the following pixels go to the
+// next one. Channel 0 is reserved for broadcasts and cannot be mapped.
+func (s *OPCServer) Handle(channel byte, strips ...Strip) error {
+	if channel == opcBroadcast {
+		return errors.New("opc channel 0 is reserved for broadcasts")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel] = strips
+	return nil
+}
+
+// SetBrightness sets the brightness (0 to 31) of the pixels received
+// from now on, OPC colors having no alpha component. Higher values are
+// full brightness. It can be called while serving.
+func (s *OPCServer) SetBrightness(b byte) {
+	if b > 31 {
+		b = 31
+	}
+	s.mu.Lock()
+	s.brightness = b
+	s.mu.Unlock()
+}
+
+// SetColorCorrection sets the color correction applied to the pixels
+// received from now on.
+func (s *OPCServer) SetColorCorrection(c ColorCorrection) {
+	var lut [3][256]byte
+	for ch := range lut {
+		for i := range lut[ch] {
+			v := float64(i) / 255
+			if v < c.LinearCutoff {
+				v *= c.LinearSlope
+			} else {
+				v = math.Pow(v, c.Gamma) * c.WhitePoint[ch]
+			}
+			lut[ch][i] = byte(math.Max(0, math.Min(255, math.Floor(v*255+0.5))))
+		}
+	}
+	s.mu.Lock()
+	s.lut = lut
+	s.mu.Unlock()
+}
+
+// ListenAndServe listens on the TCP network address addr and serves
+// OPC clients. If addr is empty, ":7890" is used.
+func (s *OPCServer) ListenAndServe(addr string) error {
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", OPCPort)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts OPC clients on the listener, each of them is handled in
+// its own goroutine. Serve blocks until the listener fails or the server
+// is closed, in which case it returns nil.
+func (s *OPCServer) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return nil
+	}
+	s.ln = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the server and disconnects all the clients. The LED strips
+// are not closed.
+func (s *OPCServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	if s.ln != nil {
+		return s.ln.Close()
+	}
+	return nil
+}
+
+func (s *OPCServer) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	var header [4]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint16(header[2:]))
+		if _, err := io.ReadFull(r, data); err != nil {
+			return
+		}
+		if err := s.handleMessage(header[0], header[1], data); err != nil {
+			return
+		}
+	}
+}
+
+func (s *OPCServer) handleMessage(channel, command byte, data []byte) error {
+	switch command {
+	case opcSetPixelColors:
+		return s.setPixelColors(channel, data)
+	case opcSystemExclusive:
+		return s.systemExclusive(data)
+	}
+	// Unknown commands must be ignored by OPC servers.
+	return nil
+}
+
+func (s *OPCServer) setPixelColors(channel byte, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if channel != opcBroadcast {
+		return s.drawChannel(s.channels[channel], data)
+	}
+	for _, strips := range s.channels {
+		if err := s.drawChannel(strips, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// drawChannel lays out the RGB triplets in data on the strips and draws
+// the strips that were updated. s.mu must be held.
+func (s *OPCServer) drawChannel(strips []Strip, data []byte) error {
+	for _, d := range strips {
+		if len(data) < 3 {
+			break
+		}
+		for i := 0; i < d.Len(); i++ {
+			if len(data) < 3 {
+				break
+			}
+			d.SetRGBA(i, RGBA{
+				R: s.lut[0][data[0]],
+				G: s.lut[1][data[1]],
+				B: s.lut[2][data[2]],
+				A: s.brightness,
+			})
+			data = data[3:]
+		}
+		if err := d.Draw(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *OPCServer) systemExclusive(data []byte) error {
+	if len(data) < 4 || binary.BigEndian.Uint16(data) != fcSystemID {
+		return nil
+	}
+	switch binary.BigEndian.Uint16(data[2:]) {
+	case fcSetGlobalColorCorrection:
+		// Malformed color corrections are ignored, like Fadecandy does.
+		c := DefaultColorCorrection
+		if err := json.Unmarshal(data[4:], &c); err == nil {
+			s.SetColorCorrection(c)
+		}
+	case fcSetFirmwareConfiguration:
+		// Nothing to configure on SPI strips.
+	}
+	return nil
+}
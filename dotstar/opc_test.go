@@ -0,0 +1,149 @@
+package dotstar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func startOPCServer(t *testing.T) (*OPCServer, net.Conn) {
+	s := NewOPCServer()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Serve(ln)
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, c
+}
+
+func sendOPC(t *testing.T, c net.Conn, channel, command byte, data []byte) {
+	msg := []byte{channel, command, 0, 0}
+	binary.BigEndian.PutUint16(msg[2:], uint16(len(data)))
+	if _, err := c.Write(append(msg, data...)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func nextFrame(t *testing.T, bus *devicetest.SPI) []byte {
+	return waitWrites(t, bus, 1)[0]
+}
+
+func TestOPCSetPixelColors(t *testing.T) {
+	d1, bus1 := openLEDs(t, 2)
+	d2, bus2 := openLEDs(t, 1)
+	s, c := startOPCServer(t)
+	defer s.Close()
+	s.SetBrightness(16)
+	if err := s.Handle(1, d1, d2); err != nil {
+		t.Fatal(err)
+	}
+
+	sendOPC(t, c, 1, opcSetPixelColors, []byte{
+		1, 2, 3,
+		4, 5, 6,
+		7, 8, 9,
+	})
+	want1 := []byte{
+		0, 0, 0, 0,
+		0xf0, 3, 2, 1,
+		0xf0, 6, 5, 4,
+		0xff, 0xff,
+	}
+	if got := nextFrame(t, bus1); !bytes.Equal(got, want1) {
+		t.Errorf("first strip got = %v, want = %v", got, want1)
+	}
+	want2 := []byte{
+		0, 0, 0, 0,
+		0xf0, 9, 8, 7,
+		0xff,
+	}
+	if got := nextFrame(t, bus2); !bytes.Equal(got, want2) {
+		t.Errorf("second strip got = %v, want = %v", got, want2)
+	}
+}
+
+func TestOPCSetBrightness(t *testing.T) {
+	s := NewOPCServer()
+	s.SetBrightness(200)
+	if s.brightness != 31 {
+		t.Errorf("brightness = %d, want = 31", s.brightness)
+	}
+}
+
+func TestOPCBroadcast(t *testing.T) {
+	d1, bus1 := openLEDs(t, 1)
+	d2, bus2 := openLEDs(t, 1)
+	s, c := startOPCServer(t)
+	defer s.Close()
+	s.Handle(1, d1)
+	s.Handle(2, d2)
+
+	sendOPC(t, c, 0, opcSetPixelColors, []byte{10, 20, 30})
+	want := []byte{0, 0, 0, 0, 0xff, 30, 20, 10, 0xff}
+	for _, bus := range []*devicetest.SPI{bus1, bus2} {
+		if got := nextFrame(t, bus); !bytes.Equal(got, want) {
+			t.Errorf("got = %v, want = %v", got, want)
+		}
+	}
+}
+
+func TestOPCColorCorrection(t *testing.T) {
+	d, bus := openLEDs(t, 1)
+	s, c := startOPCServer(t)
+	defer s.Close()
+	s.Handle(1, d)
+
+	sysex := []byte{0x00, 0x01, 0x00, 0x01}
+	sysex = append(sysex, `{"gamma": 2.0, "whitepoint": [1.0, 0.5, 0.0]}`...)
+	sendOPC(t, c, 0, opcSystemExclusive, sysex)
+	// Firmware configuration messages are ignored.
+	sendOPC(t, c, 0, opcSystemExclusive, []byte{0x00, 0x01, 0x00, 0x02, 0x0f})
+	sendOPC(t, c, 1, opcSetPixelColors, []byte{255, 255, 128})
+
+	want := []byte{0, 0, 0, 0, 0xff, 0, 128, 255, 0xff}
+	if got := nextFrame(t, bus); !bytes.Equal(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestOPCHandleBroadcastChannel(t *testing.T) {
+	s := NewOPCServer()
+	if err := s.Handle(0); err == nil {
+		t.Error("mapping channel 0 should fail")
+	}
+}
+
+func TestOPCClose(t *testing.T) {
+	s := NewOPCServer()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() { done <- s.Serve(ln) }()
+
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Serve returned %v after Close", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Serve didn't return after Close")
+	}
+}
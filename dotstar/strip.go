@@ -0,0 +1,19 @@
+package dotstar
+
+// Strip is an addressable LED strip. It is implemented by LEDs and by the
+// drivers of other SPI LED strips, such as the ws2801 and lpd8806
+// packages, so that effects and mappings can drive any of them.
+type Strip interface {
+	// Len returns the number of LEDs on the strip.
+	Len() int
+
+	// SetRGBA sets the ith LED's color. A call to Draw is required to
+	// transmit the new value to the strip.
+	SetRGBA(i int, v RGBA)
+
+	// Draw displays the colors set on the actual LED strip.
+	Draw() error
+
+	// Close frees the underlying resources.
+	Close() error
+}
@@ -0,0 +1,214 @@
+package hd44780
+
+import "fmt"
+
+// CustomChars is a map of CGRAM characters that can be loaded
+// into a LCD screen to display custom characters. Some LCD screens such
+// as the Grove screen (jhd1313m1) isn't loaded with latin 1 characters.
+// It's up to the developer to load the set up to 8 custom characters and
+// update the input text so the character is swapped by a byte reflecting
+// the position of the custom character to use.
+// Each byte is a row of 5 pixels, the most significant of the 5 low bits
+// being the leftmost pixel, see FormatGlyph.
+// See SetCustomChar
+var CustomChars = map[string][8]byte{
+	"é":       [8]byte{2, 4, 14, 17, 31, 16, 14, 0},
+	"è":       [8]byte{8, 4, 14, 17, 31, 16, 14, 0},
+	"ê":       [8]byte{4, 10, 14, 17, 31, 16, 14, 0},
+	"à":       [8]byte{8, 6, 0, 14, 17, 19, 13, 0},
+	"â":       [8]byte{4, 10, 0, 14, 17, 19, 13, 0},
+	"á":       [8]byte{2, 4, 14, 1, 15, 17, 15, 0},
+	"î":       [8]byte{4, 10, 0, 12, 4, 4, 14, 0},
+	"í":       [8]byte{2, 4, 12, 4, 4, 4, 14, 0},
+	"û":       [8]byte{4, 10, 0, 17, 17, 19, 13, 0},
+	"ù":       [8]byte{8, 6, 0, 17, 17, 19, 13, 0},
+	"ñ":       [8]byte{14, 0, 22, 25, 17, 17, 17, 0},
+	"ó":       [8]byte{2, 4, 14, 17, 17, 17, 14, 0},
+	"heart":   [8]byte{0, 10, 31, 31, 31, 14, 4, 0},
+	"smiley":  [8]byte{0, 0, 10, 0, 0, 17, 14, 0},
+	"frowney": [8]byte{0, 0, 10, 0, 0, 0, 14, 17},
+
+	// partial blocks of the horizontal bars, 1 to 4 columns
+	"▏": [8]byte{16, 16, 16, 16, 16, 16, 16, 16},
+	"▎": [8]byte{24, 24, 24, 24, 24, 24, 24, 24},
+	"▍": [8]byte{28, 28, 28, 28, 28, 28, 28, 28},
+	"▌": [8]byte{30, 30, 30, 30, 30, 30, 30, 30},
+	// partial blocks of the vertical bars, 1 to 7 rows
+	"▁": [8]byte{0, 0, 0, 0, 0, 0, 0, 31},
+	"▂": [8]byte{0, 0, 0, 0, 0, 0, 31, 31},
+	"▃": [8]byte{0, 0, 0, 0, 0, 31, 31, 31},
+	"▄": [8]byte{0, 0, 0, 0, 31, 31, 31, 31},
+	"▅": [8]byte{0, 0, 0, 31, 31, 31, 31, 31},
+	"▆": [8]byte{0, 0, 31, 31, 31, 31, 31, 31},
+	"▇": [8]byte{0, 31, 31, 31, 31, 31, 31, 31},
+	// segments of the big digits
+	"⎺": [8]byte{31, 31, 31, 0, 0, 0, 0, 0},
+	"⎽": [8]byte{0, 0, 0, 0, 0, 31, 31, 31},
+	"≡": [8]byte{31, 31, 31, 0, 0, 31, 31, 31},
+	// the ROM has a yen sign instead of a backslash
+	"╲": [8]byte{0, 16, 8, 4, 2, 1, 0, 0},
+}
+
+// romChars maps the non-ASCII characters found in the A00 character ROM
+// of the HD44780 compatible controllers to their code.
+var romChars = map[rune]byte{
+	'¥': 0x5c,
+	'→': 0x7e,
+	'←': 0x7f,
+	'°': 0xdf,
+	'α': 0xe0,
+	'ä': 0xe1,
+	'β': 0xe2,
+	'ε': 0xe3,
+	'μ': 0xe4,
+	'σ': 0xe5,
+	'ρ': 0xe6,
+	'√': 0xe8,
+	'¢': 0xec,
+	'ñ': 0xee,
+	'ö': 0xef,
+	'θ': 0xf2,
+	'∞': 0xf3,
+	'Ω': 0xf4,
+	'ü': 0xf5,
+	'Σ': 0xf6,
+	'π': 0xf7,
+	'÷': 0xfd,
+	'█': 0xff,
+}
+
+// romCode returns the ROM code of r, if any. Half-width katakana
+// (U+FF61 to U+FF9F) are at 0xA1 to 0xDF, like in JIS X 0201.
+func romCode(r rune) (byte, bool) {
+	if r >= 0xff61 && r <= 0xff9f {
+		return byte(r - 0xff61 + 0xa1), true
+	}
+	c, ok := romChars[r]
+	return c, ok
+}
+
+// cgSlot describes the content of a CGRAM slot.
+type cgSlot struct {
+	glyph    string // key of the glyph in the Glyphs table, if any
+	used     uint64 // tick of the last Write using the slot
+	reserved bool   // set by SetCustomChar, never evicted
+}
+
+// mapRunes converts message to the character codes to write, loading the
+// missing glyphs into CGRAM. Control characters are mapped to -1. If keep
+// is set, the slots of the glyphs shown on the display are kept, else the
+// caller redraws every cell.
+func (d *Device) mapRunes(message string, keep bool) ([]int, error) {
+	d.tick++
+	codes := make([]int, 0, len(message))
+	var glyphs []string            // glyphs to load, in order of appearance
+	needed := make(map[string]int) // index of the glyphs in glyphs
+	for _, r := range message {
+		switch {
+		case isControl(r):
+			codes = append(codes, -1)
+			continue
+		case r < 0x80:
+			codes = append(codes, int(r))
+			continue
+		}
+		if c, ok := romCode(r); ok {
+			codes = append(codes, int(c))
+			continue
+		}
+		key := string(r)
+		g, ok := d.Glyphs[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownGlyph, r)
+		}
+		if err := ValidateGlyph(g); err != nil {
+			return nil, fmt.Errorf("glyph %q: %w", r, err)
+		}
+		i, ok := needed[key]
+		if !ok {
+			i = len(glyphs)
+			needed[key] = i
+			glyphs = append(glyphs, key)
+		}
+		// resolved to a CGRAM slot below
+		codes = append(codes, -2-i)
+	}
+
+	var shown [8]bool
+	if keep {
+		shown = d.shownSlots()
+	}
+	free := 0
+	for i, s := range d.cgram {
+		if _, ok := needed[s.glyph]; !s.reserved && (!shown[i] || ok) {
+			free++
+		}
+	}
+	if len(glyphs) > free {
+		return nil, ErrTooManyGlyphs
+	}
+
+	slots := make([]int, len(glyphs))
+	loaded := false
+	for i, key := range glyphs {
+		pos := d.glyphSlot(key, needed, shown)
+		if d.cgram[pos].glyph != key {
+			if err := d.loadGlyph(pos, d.Glyphs[key]); err != nil {
+				return nil, err
+			}
+			loaded = true
+		}
+		d.cgram[pos] = cgSlot{glyph: key, used: d.tick}
+		slots[i] = pos
+	}
+	if loaded {
+		if err := d.restoreAddr(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, c := range codes {
+		if c <= -2 {
+			codes[i] = slots[-2-c]
+		}
+	}
+	return codes, nil
+}
+
+// shownSlots reports which CGRAM slots are shown in the cells of the
+// display, codes 8 to 15 being aliases of codes 0 to 7.
+func (d *Device) shownSlots() [8]bool {
+	var shown [8]bool
+	for _, row := range d.text {
+		for _, c := range row {
+			if c < 16 {
+				shown[c&7] = true
+			}
+		}
+	}
+	return shown
+}
+
+// glyphSlot returns the CGRAM slot to use for the glyph key: the slot
+// already holding it, else an empty slot, else the least recently used
+// slot neither needed by the current message nor shown.
+func (d *Device) glyphSlot(key string, needed map[string]int, shown [8]bool) int {
+	for i, s := range d.cgram {
+		if !s.reserved && s.glyph == key {
+			return i
+		}
+	}
+	best := -1
+	for i, s := range d.cgram {
+		if _, ok := needed[s.glyph]; ok || s.reserved || shown[i] {
+			continue
+		}
+		if s.glyph == "" {
+			return i
+		}
+		if best < 0 || s.used < d.cgram[best].used {
+			best = i
+		}
+	}
+	return best
+}
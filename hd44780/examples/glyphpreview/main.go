@@ -0,0 +1,62 @@
+// The glyphpreview command shows custom LCD glyphs in the terminal.
+//
+// It reads the glyph set files given as arguments, or shows the default
+// glyphs of the hd44780 package if there are none:
+//
+//	glyphpreview arrows.glyphs
+//	glyphpreview -name heart
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/goiot/devices/hd44780"
+)
+
+var name = flag.String("name", "", "only show the glyph with this name")
+
+func main() {
+	flag.Parse()
+	log.SetFlags(0)
+
+	glyphs := hd44780.CustomChars
+	if flag.NArg() > 0 {
+		glyphs = make(map[string][8]byte)
+		for _, filename := range flag.Args() {
+			set, err := hd44780.LoadGlyphs(filename)
+			if err != nil {
+				log.Fatal(err)
+			}
+			for k, v := range set {
+				glyphs[k] = v
+			}
+		}
+	}
+
+	var names []string
+	for k := range glyphs {
+		if *name == "" || k == *name {
+			names = append(names, k)
+		}
+	}
+	if len(names) == 0 {
+		log.Fatalf("no glyph named %q", *name)
+	}
+	sort.Strings(names)
+
+	for _, k := range names {
+		g := glyphs[k]
+		fmt.Printf("[%s] % x\n", k, g[:])
+		if err := hd44780.ValidateGlyph(g); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		// Two characters per pixel to keep the proportions.
+		art := strings.NewReplacer("#", "██", ".", "··").Replace(hd44780.FormatGlyph(g))
+		fmt.Println(art)
+	}
+}
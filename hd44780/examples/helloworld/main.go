@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/goiot/devices/hd44780"
+	"golang.org/x/exp/io/i2c"
+)
+
+func main() {
+	backpack, err := hd44780.OpenPCF8574(&i2c.Devfs{Dev: "/dev/i2c-1"}, hd44780.PCF8574Addr)
+	if err != nil {
+		panic(err)
+	}
+	display, err := hd44780.New(backpack, 20, 4)
+	if err != nil {
+		panic(err)
+	}
+	defer display.Close()
+
+	for row, text := range []string{"Hello World!", "20x4 HD44780", "on a PCF8574", "backpack"} {
+		display.SetCursor(0, row)
+		display.WriteString(text)
+	}
+
+	time.Sleep(5 * time.Second)
+	backpack.SetBacklight(false)
+	display.Clear()
+}
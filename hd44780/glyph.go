@@ -0,0 +1,214 @@
+package hd44780
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidGlyph is returned when a glyph row uses more than the 5 low
+// bits, the glyphs being 5 pixels wide.
+var ErrInvalidGlyph = errors.New("invalid glyph, only the 5 low bits of each row can be set")
+
+// Glyph art pixels.
+const (
+	pixelOn  = '#'
+	pixelOff = '.'
+)
+
+// ValidateGlyph checks that the rows of g only use their 5 low bits.
+func ValidateGlyph(g [8]byte) error {
+	for i, row := range g {
+		if row&^0x1f != 0 {
+			return fmt.Errorf("%w: row %d is %#02x", ErrInvalidGlyph, i, row)
+		}
+	}
+	return nil
+}
+
+// ParseGlyph converts text art to a glyph. The art is 8 lines of 5
+// pixels, '#' for the pixels that are on and '.' for the ones that are
+// off. Leading and trailing spaces and blank lines are ignored:
+//
+//	heart, _ := hd44780.ParseGlyph(`
+//		.....
+//		.#.#.
+//		#####
+//		#####
+//		#####
+//		.###.
+//		..#..
+//		.....
+//	`)
+func ParseGlyph(art string) ([8]byte, error) {
+	var g [8]byte
+	rows := 0
+	for _, line := range strings.Split(art, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if rows == len(g) {
+			return g, fmt.Errorf("glyph art has more than %d rows", len(g))
+		}
+		row, err := parseGlyphRow(line)
+		if err != nil {
+			return g, err
+		}
+		g[rows] = row
+		rows++
+	}
+	if rows != len(g) {
+		return g, fmt.Errorf("glyph art has %d rows, want %d", rows, len(g))
+	}
+	return g, nil
+}
+
+// MustParseGlyph is like ParseGlyph but panics if the art is invalid. It
+// simplifies the initialization of glyph tables.
+func MustParseGlyph(art string) [8]byte {
+	g, err := ParseGlyph(art)
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+func parseGlyphRow(line string) (byte, error) {
+	if len(line) != 5 {
+		return 0, fmt.Errorf("glyph art row %q is not 5 pixels wide", line)
+	}
+	var row byte
+	for _, c := range line {
+		row <<= 1
+		switch c {
+		case pixelOn:
+			row |= 1
+		case pixelOff:
+		default:
+			return 0, fmt.Errorf("invalid pixel %q in glyph art row %q, want '%c' or '%c'", c, line, pixelOn, pixelOff)
+		}
+	}
+	return row, nil
+}
+
+// FormatGlyph returns the text art of g, see ParseGlyph. The bits above
+// the 5 low ones are ignored.
+func FormatGlyph(g [8]byte) string {
+	var buf bytes.Buffer
+	for _, row := range g {
+		for bit := byte(0x10); bit != 0; bit >>= 1 {
+			if row&bit != 0 {
+				buf.WriteByte(pixelOn)
+			} else {
+				buf.WriteByte(pixelOff)
+			}
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.String()
+}
+
+// ReadGlyphs reads a glyph set. Each glyph starts with its name between
+// square brackets on its own line, followed by its art (see ParseGlyph).
+// Blank lines and lines starting with "//" are ignored:
+//
+//	// arrows
+//	[↑]
+//	..#..
+//	.###.
+//	#.#.#
+//	..#..
+//	..#..
+//	..#..
+//	..#..
+//	.....
+//
+// Names are usually single characters so that the glyphs can be added to
+// the Glyphs table of a display.
+func ReadGlyphs(r io.Reader) (map[string][8]byte, error) {
+	glyphs := make(map[string][8]byte)
+	var name string
+	var art []string
+	flush := func() error {
+		if name == "" {
+			return nil
+		}
+		if _, ok := glyphs[name]; ok {
+			return fmt.Errorf("glyph %q is defined twice", name)
+		}
+		g, err := ParseGlyph(strings.Join(art, "\n"))
+		if err != nil {
+			return fmt.Errorf("glyph %q: %v", name, err)
+		}
+		glyphs[name] = g
+		return nil
+	}
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "//"):
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			name, art = line[1:len(line)-1], nil
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty glyph name", n)
+			}
+		case name == "":
+			return nil, fmt.Errorf("line %d: glyph art before the first glyph name", n)
+		default:
+			art = append(art, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return glyphs, nil
+}
+
+// LoadGlyphs reads the glyph set file, see ReadGlyphs.
+func LoadGlyphs(filename string) (map[string][8]byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	glyphs, err := ReadGlyphs(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return glyphs, nil
+}
+
+// WriteGlyphs writes the glyphs as a glyph set sorted by name, in the
+// format read by ReadGlyphs.
+func WriteGlyphs(w io.Writer, glyphs map[string][8]byte) error {
+	names := make([]string, 0, len(glyphs))
+	for name := range glyphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i, name := range names {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "[%s]\n%s", name, FormatGlyph(glyphs[name])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
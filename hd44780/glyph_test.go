@@ -0,0 +1,113 @@
+package hd44780
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const heartArt = `
+	.....
+	.#.#.
+	#####
+	#####
+	#####
+	.###.
+	..#..
+	.....
+`
+
+func TestParseGlyph(t *testing.T) {
+	g, err := ParseGlyph(heartArt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := CustomChars["heart"]; g != want {
+		t.Errorf("got %v, want %v", g, want)
+	}
+	if art := FormatGlyph(g); art != strings.Replace(strings.TrimLeft(heartArt, "\n"), "\t", "", -1) {
+		t.Errorf("FormatGlyph returned\n%s", art)
+	}
+}
+
+func TestParseGlyphErrors(t *testing.T) {
+	for _, art := range []string{
+		".....\n.....",
+		strings.Repeat(".....\n", 9),
+		strings.Repeat("......\n", 8),
+		strings.Repeat("..x..\n", 8),
+	} {
+		if _, err := ParseGlyph(art); err == nil {
+			t.Errorf("ParseGlyph(%q) should fail", art)
+		}
+	}
+}
+
+func TestValidateGlyph(t *testing.T) {
+	for name, g := range CustomChars {
+		if err := ValidateGlyph(g); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if err := ValidateGlyph([8]byte{0, 0, 0x20}); !errors.Is(err, ErrInvalidGlyph) {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidGlyph)
+	}
+
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.SetCustomChar(0, [8]byte{130, 132, 142, 145, 159, 144, 142, 128}); !errors.Is(err, ErrInvalidGlyph) {
+		t.Errorf("SetCustomChar got = %v, want = %v", err, ErrInvalidGlyph)
+	}
+	d.Glyphs["€"] = [8]byte{0xff}
+	if _, err := d.WriteString("€"); !errors.Is(err, ErrInvalidGlyph) {
+		t.Errorf("Write got = %v, want = %v", err, ErrInvalidGlyph)
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("nothing should be written on error, got %d transactions", n)
+	}
+}
+
+func TestLoadGlyphs(t *testing.T) {
+	glyphs, err := LoadGlyphs("testdata/arrows.glyphs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][8]byte{
+		"↑": {4, 14, 21, 4, 4, 4, 4, 0},
+		"↓": {4, 4, 4, 4, 21, 14, 4, 0},
+	}
+	if !reflect.DeepEqual(glyphs, want) {
+		t.Errorf("got %v, want %v", glyphs, want)
+	}
+
+	// WriteGlyphs writes what ReadGlyphs reads.
+	var buf bytes.Buffer
+	if err := WriteGlyphs(&buf, glyphs); err != nil {
+		t.Fatal(err)
+	}
+	again, err := ReadGlyphs(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(again, want) {
+		t.Errorf("got %v after a round trip, want %v", again, want)
+	}
+}
+
+func TestReadGlyphsErrors(t *testing.T) {
+	for _, set := range []string{
+		".....\n[a]\n" + strings.Repeat(".....\n", 8),
+		"[]\n" + strings.Repeat(".....\n", 8),
+		"[a]\n" + strings.Repeat(".....\n", 7),
+		"[a]\n" + strings.Repeat(".....\n", 8) + "[a]\n" + strings.Repeat(".....\n", 8),
+	} {
+		if _, err := ReadGlyphs(strings.NewReader(set)); err == nil {
+			t.Errorf("ReadGlyphs(%q) should fail", set)
+		}
+	}
+	if _, err := LoadGlyphs("testdata/missing.glyphs"); err == nil {
+		t.Error("LoadGlyphs should fail for missing files")
+	}
+}
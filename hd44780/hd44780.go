@@ -0,0 +1,254 @@
+// Package hd44780 implements a driver for the HD44780 compatible
+// character LCD controllers.
+//
+// The controller is driven through a Transport. This package provides the
+// transports of the JHD1313 I2C controller used by the Grove LCDs and of
+// the common PCF8574 I2C backpacks driving the controller in 4-bit mode.
+package hd44780
+
+import (
+	"fmt"
+	"time"
+)
+
+// Transport sends instructions and data to the controller.
+type Transport interface {
+	// Init prepares the controller interface after power on, for instance
+	// by switching it to 4-bit mode. It is called once by New before any
+	// instruction is sent.
+	Init() error
+	// Command sends an instruction.
+	Command(cmd byte) error
+	// Data writes data to the CGRAM or DDRAM.
+	Data(data ...byte) error
+	// Close closes the connection to the controller.
+	Close() error
+}
+
+// Device is an HD44780 compatible character LCD.
+type Device struct {
+	// Glyphs holds the glyphs loaded into CGRAM by Write for the
+	// characters missing from the controller's ROM. It is initialized with
+	// CustomChars, keys are single characters.
+	Glyphs map[string][8]byte
+
+	// ScrollLines makes Write scroll the rows up when the text goes past
+	// the last row, the last row is then blank. Otherwise the text
+	// continues on the first row.
+	ScrollLines bool
+
+	t          Transport
+	cols, rows int
+	offsets    []byte // DDRAM address of the first character of each row
+
+	// current display control and entry mode flags
+	displayControl byte
+	entryMode      byte
+
+	addr  byte      // current DDRAM address
+	cgram [8]cgSlot // CGRAM slots content
+	tick  uint64    // incremented by every Write, used for LRU eviction
+
+	text     [][]byte // codes shown in each cell, used to scroll
+	col, row int      // cursor position of Write
+	wrap     bool     // the cursor is past the end of the row
+}
+
+// New initializes the controller behind the transport for a display of
+// cols columns and rows rows, such as 16x2 or 20x4. Displays of up to 4
+// rows of 20 characters, or 2 rows of 40 characters, are supported.
+// New uses DefaultTiming, initialization errors are of type *InitError.
+// The transport is left open on errors.
+func New(t Transport, cols, rows int) (*Device, error) {
+	return NewWithTiming(t, cols, rows, DefaultTiming)
+}
+
+// NewWithTiming is like New but uses the given initialization timing.
+func NewWithTiming(t Transport, cols, rows int, timing Timing) (*Device, error) {
+	offsets, err := rowOffsets(cols, rows)
+	if err != nil {
+		return nil, err
+	}
+	d := &Device{
+		t:              t,
+		cols:           cols,
+		rows:           rows,
+		offsets:        offsets,
+		displayControl: lcdDisplayOn | lcdCursorOff | lcdBlinkOff,
+		entryMode:      lcdEntryLeft | lcdEntryShiftDecrement,
+		Glyphs:         make(map[string][8]byte, len(CustomChars)),
+		text:           make([][]byte, rows),
+	}
+	for row := range d.text {
+		d.text[row] = make([]byte, cols)
+	}
+	for k, v := range CustomChars {
+		d.Glyphs[k] = v
+	}
+
+	// wait for the controller to power up
+	time.Sleep(timing.PowerOn)
+	if err := timing.Retry(func() *InitError { return d.init(timing) }); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Cols returns the number of characters per row.
+func (d *Device) Cols() int {
+	return d.cols
+}
+
+// Rows returns the number of rows.
+func (d *Device) Rows() int {
+	return d.rows
+}
+
+// Clear clears the text on the display.
+func (d *Device) Clear() error {
+	err := d.command(lcdClearDisplay)
+	// The controller takes 1.52ms to clear the display.
+	time.Sleep(2 * time.Millisecond)
+	if err != nil {
+		return err
+	}
+	// Clearing moves the cursor home and restores the left to right entry.
+	d.addr = 0
+	d.entryMode |= lcdEntryLeft
+	d.home()
+	for row := range d.text {
+		for col := range d.text[row] {
+			d.text[row][col] = ' '
+		}
+	}
+	return nil
+}
+
+// Home sets the cursor to the origin position on the display.
+func (d *Device) Home() error {
+	err := d.command(lcdReturnHome)
+	// This wait fixes a race condition when calling home and clear back to back.
+	time.Sleep(2 * time.Millisecond)
+	if err == nil {
+		d.addr = 0
+		d.home()
+	}
+	return err
+}
+
+// SetPosition sets the cursor and the data display to pos, counting the
+// characters row after row: on a 16x2 display, 0..15 are the positions in
+// the first row and 16..31 the positions in the second row.
+func (d *Device) SetPosition(pos int) error {
+	if pos < 0 || pos >= d.cols*d.rows {
+		return ErrInvalidPosition
+	}
+	return d.SetCursor(pos%d.cols, pos/d.cols)
+}
+
+// SetCursor sets the cursor and the data display to the column col of the
+// row, both starting at 0.
+func (d *Device) SetCursor(col, row int) error {
+	if col < 0 || col >= d.cols || row < 0 || row >= d.rows {
+		return ErrInvalidPosition
+	}
+	addr := d.offsets[row] + byte(col)
+	if err := d.command(lcdSetDdramAddr | addr); err != nil {
+		return err
+	}
+	d.addr = addr
+	d.col, d.row, d.wrap = col, row, false
+	return nil
+}
+
+// Scroll scrolls the text on the display
+func (d *Device) Scroll(leftToRight bool) error {
+	if leftToRight {
+		return d.command(lcdCursorShift | lcdDisplayMove | lcdMoveLeft)
+	}
+
+	return d.command(lcdCursorShift | lcdDisplayMove | lcdMoveRight)
+}
+
+// On turns the display on, the text shown before turning it off is
+// displayed again.
+func (d *Device) On() error {
+	return d.setDisplayControl(lcdDisplayOn, true)
+}
+
+// Off turns the display off without clearing its content.
+func (d *Device) Off() error {
+	return d.setDisplayControl(lcdDisplayOn, false)
+}
+
+// CursorOn shows the underline cursor.
+func (d *Device) CursorOn() error {
+	return d.setDisplayControl(lcdCursorOn, true)
+}
+
+// CursorOff hides the underline cursor.
+func (d *Device) CursorOff() error {
+	return d.setDisplayControl(lcdCursorOn, false)
+}
+
+// BlinkOn turns on the blinking block cursor.
+func (d *Device) BlinkOn() error {
+	return d.setDisplayControl(lcdBlinkOn, true)
+}
+
+// BlinkOff turns off the blinking block cursor.
+func (d *Device) BlinkOff() error {
+	return d.setDisplayControl(lcdBlinkOn, false)
+}
+
+// LeftToRight makes the text flow to the right of the cursor (default).
+func (d *Device) LeftToRight() error {
+	return d.setEntryMode(lcdEntryLeft, true)
+}
+
+// RightToLeft makes the text flow to the left of the cursor.
+func (d *Device) RightToLeft() error {
+	return d.setEntryMode(lcdEntryLeft, false)
+}
+
+// AutoscrollOn shifts the display on each written character so that the
+// cursor stays in place and the text moves, like a ticker.
+func (d *Device) AutoscrollOn() error {
+	return d.setEntryMode(lcdEntryShiftIncrement, true)
+}
+
+// AutoscrollOff stops shifting the display when characters are written
+// (default).
+func (d *Device) AutoscrollOff() error {
+	return d.setEntryMode(lcdEntryShiftIncrement, false)
+}
+
+// SetCustomChar sets one of the 8 CGRAM locations with a custom character.
+// The custom character can be used by writing a byte of value 0 to 7.
+// When you are using LCD as 5x8 dots in function set then you can define a total of 8 user defined patterns
+// (1 Byte for each row and 8 rows for each pattern).
+// Use http://www.8051projects.net/lcd-interfacing/lcd-custom-character.php to create your own
+// characters.
+// To use a custom character, write byte value of the custom character position as a string after
+// having setup the custom character.
+// Locations set by hand are reserved and never used by the automatic
+// mapping of Write. Only the 5 low bits of each row can be set, see
+// ParseGlyph to define characters from text art.
+func (d *Device) SetCustomChar(pos int, charMap [8]byte) error {
+	if pos < 0 || pos > 7 {
+		return fmt.Errorf("can't set a custom character at position %d, it must be between 0 and 7", pos)
+	}
+	if err := ValidateGlyph(charMap); err != nil {
+		return err
+	}
+	if err := d.loadGlyph(pos, charMap); err != nil {
+		return err
+	}
+	d.cgram[pos] = cgSlot{reserved: true}
+	return d.restoreAddr()
+}
+
+// Close closes the transport, the display content is left as is.
+func (d *Device) Close() error {
+	return d.t.Close()
+}
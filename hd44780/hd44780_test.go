@@ -0,0 +1,281 @@
+package hd44780
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func openSize(t *testing.T, cols, rows int) (*Device, *devicetest.I2C) {
+	bus := &devicetest.I2C{}
+	tr, err := OpenJHD1313(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := NewWithTiming(tr, cols, rows, Timing{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, bus
+}
+
+// openDisplay returns a 16x2 display on a JHD1313 controller.
+func openDisplay(t *testing.T) (*Device, *devicetest.I2C) {
+	return openSize(t, 16, 2)
+}
+
+func TestNewInvalidSize(t *testing.T) {
+	for _, size := range [][2]int{{0, 2}, {41, 2}, {16, 0}, {21, 4}, {16, 5}} {
+		bus := &devicetest.I2C{}
+		tr, err := OpenJHD1313(bus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := New(tr, size[0], size[1]); err == nil {
+			t.Errorf("New should reject a %dx%d display", size[0], size[1])
+		}
+		if n := len(bus.Txs()); n != 0 {
+			t.Errorf("%dx%d: nothing should be sent, got %d transactions", size[0], size[1], n)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if _, err := d.WriteString("Hi\nyo"); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "write")
+}
+
+func TestSetPosition(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	for _, pos := range []int{0, 15, 16, 31} {
+		if err := d.SetPosition(pos); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, pos := range []int{-1, 32} {
+		if err := d.SetPosition(pos); err != ErrInvalidPosition {
+			t.Errorf("SetPosition(%d) = %v, want = %v", pos, err, ErrInvalidPosition)
+		}
+	}
+	bus.CheckGolden(t, "position")
+}
+
+func TestSetCursor20x4(t *testing.T) {
+	d, bus := openSize(t, 20, 4)
+	bus.Reset()
+	for row := 0; row < 4; row++ {
+		if err := d.SetCursor(19, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.SetPosition(79); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range [][2]int{{20, 0}, {0, 4}, {-1, 0}} {
+		if err := d.SetCursor(c[0], c[1]); err != ErrInvalidPosition {
+			t.Errorf("SetCursor(%d, %d) = %v, want = %v", c[0], c[1], err, ErrInvalidPosition)
+		}
+	}
+	if err := d.SetPosition(80); err != ErrInvalidPosition {
+		t.Errorf("SetPosition(80) = %v, want = %v", err, ErrInvalidPosition)
+	}
+	want := "80 93\n80 d3\n80 a7\n80 e7\n80 e7"
+	if got := stream(bus.Writes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestSetCustomChar(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.SetCustomChar(3, CustomChars["heart"]); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetCustomChar(8, CustomChars["heart"]); err == nil {
+		t.Error("CGRAM positions above 7 should be rejected")
+	}
+	bus.CheckGolden(t, "customchar")
+}
+
+func TestDisplayControl(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	for _, f := range []func() error{
+		d.CursorOn,
+		d.BlinkOn,
+		d.Off,
+		d.On,
+		d.CursorOff,
+		d.BlinkOff,
+	} {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bus.CheckGolden(t, "control")
+}
+
+func TestEntryMode(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	for _, f := range []func() error{
+		d.RightToLeft,
+		d.AutoscrollOn,
+		d.LeftToRight,
+		d.AutoscrollOff,
+	} {
+		if err := f(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bus.CheckGolden(t, "entrymode")
+}
+
+func TestDisplayControlError(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	bus.Fail(0, nil)
+	if err := d.CursorOn(); err == nil {
+		t.Fatal("expected an error")
+	}
+	// The failed command must not change the tracked state.
+	if err := d.BlinkOn(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := bus.Writes()[1], []byte{jhdCmd, 0x0d}; string(got) != string(want) {
+		t.Errorf("got = %x, want = %x", got, want)
+	}
+}
+
+func TestAdvance(t *testing.T) {
+	tests := []struct {
+		cols, rows int
+		from, to   byte
+		left       bool
+	}{
+		{16, 2, 0x00, 0x01, true},
+		{16, 2, 0x27, 0x40, true},
+		{16, 2, 0x67, 0x00, true},
+		{16, 2, 0x40, 0x27, false},
+		{16, 2, 0x00, 0x67, false},
+		// the first row of a 20x4 display continues on the third one
+		{20, 4, 0x13, 0x14, true},
+		{40, 1, 0x3f, 0x40, true},
+		{40, 1, 0x4f, 0x00, true},
+		{40, 1, 0x00, 0x4f, false},
+	}
+	for _, tt := range tests {
+		d := &Device{cols: tt.cols, rows: tt.rows, addr: tt.from}
+		if tt.left {
+			d.entryMode = lcdEntryLeft
+		}
+		d.advance()
+		if d.addr != tt.to {
+			t.Errorf("%dx%d: advance from %#x = %#x, want %#x", tt.cols, tt.rows, tt.from, d.addr, tt.to)
+		}
+	}
+}
+
+func TestWriteGlyphs(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	// é is loaded into CGRAM after the cursor moved, ñ and ° are in ROM.
+	if _, err := d.WriteString("ab"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteString("é ñ°é"); err != nil {
+		t.Fatal(err)
+	}
+	// é is already loaded.
+	if _, err := d.WriteString("\né"); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "glyphs")
+}
+
+func TestWriteGlyphsLRU(t *testing.T) {
+	d, _ := openDisplay(t)
+	if _, err := d.WriteString("éèêàâáîí"); err != nil {
+		t.Fatal(err)
+	}
+	// The glyphs are no longer shown but stay in CGRAM.
+	if err := d.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteString("é"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteString("ûù"); err != nil {
+		t.Fatal(err)
+	}
+	want := [8]string{"é", "û", "ù", "à", "â", "á", "î", "í"}
+	for i, s := range d.cgram {
+		if s.glyph != want[i] {
+			t.Errorf("slot %d holds %q, want %q", i, s.glyph, want[i])
+		}
+	}
+}
+
+func TestWriteTooManyGlyphs(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if _, err := d.WriteString("éèêàâáîíó"); err != ErrTooManyGlyphs {
+		t.Errorf("got = %v, want = %v", err, ErrTooManyGlyphs)
+	}
+	if err := d.SetCustomChar(0, CustomChars["heart"]); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if _, err := d.WriteString("éèêàâáîí"); err != ErrTooManyGlyphs {
+		t.Errorf("got = %v, want = %v", err, ErrTooManyGlyphs)
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("nothing should be written on error, got %d transactions", n)
+	}
+}
+
+func TestWriteShownGlyphs(t *testing.T) {
+	d, bus := openDisplay(t)
+	if _, err := d.WriteString("é"); err != nil {
+		t.Fatal(err)
+	}
+	// The é shown on the first row keeps its slot.
+	bus.Reset()
+	if _, err := d.WriteString("\nèêàâáîíó"); err != ErrTooManyGlyphs {
+		t.Errorf("got = %v, want = %v", err, ErrTooManyGlyphs)
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("nothing should be written on error, got %d transactions", n)
+	}
+	// Overwriting it frees the slot on the next Write.
+	if _, err := d.WriteString("\ra"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteString("\nèêàâáîíó"); err != nil {
+		t.Fatal(err)
+	}
+	if s := d.shownSlots(); s != [8]bool{true, true, true, true, true, true, true, true} {
+		t.Errorf("got shown slots %v, want all of them", s)
+	}
+}
+
+func TestWriteUnknownGlyph(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if _, err := d.WriteString("5€"); !errors.Is(err, ErrUnknownGlyph) {
+		t.Errorf("got = %v, want = %v", err, ErrUnknownGlyph)
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("nothing should be written on error, got %d transactions", n)
+	}
+	d.Glyphs["€"] = [8]byte{6, 9, 28, 8, 28, 9, 6, 0}
+	if _, err := d.WriteString("5€"); err != nil {
+		t.Fatal(err)
+	}
+}
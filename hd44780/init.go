@@ -0,0 +1,96 @@
+package hd44780
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timing defines the waits of the initialization sequence and how it is
+// retried. Controllers sometimes don't acknowledge the first instructions
+// after a cold boot, retrying the whole sequence gets them going.
+type Timing struct {
+	// PowerOn is the wait before the first instruction.
+	PowerOn time.Duration
+	// Step is the wait after the function set and display control
+	// instructions.
+	Step time.Duration
+	// Retries is the number of times the sequence is retried after a
+	// failure.
+	Retries int
+	// RetryDelay is the wait before each retry.
+	RetryDelay time.Duration
+}
+
+// DefaultTiming is the initialization timing used by New.
+var DefaultTiming = Timing{
+	PowerOn:    50 * time.Millisecond,
+	Step:       100 * time.Millisecond,
+	Retries:    2,
+	RetryDelay: 50 * time.Millisecond,
+}
+
+// Retry runs the initialization sequence init until it succeeds or
+// t.Retries retries failed, waiting t.RetryDelay before each retry.
+// It returns the error of the last attempt, if any.
+func (t Timing) Retry(init func() *InitError) error {
+	for attempt := 1; ; attempt++ {
+		err := init()
+		if err == nil {
+			return nil
+		}
+		if attempt > t.Retries {
+			err.Attempts = attempt
+			return err
+		}
+		time.Sleep(t.RetryDelay)
+	}
+}
+
+// InitError is returned when the initialization of a display fails.
+type InitError struct {
+	// Step is the initialization step that failed: "wake up", "function
+	// set", "display control", "clear" or "entry mode" for the HD44780
+	// controllers, drivers built on top of this package add their own.
+	Step string
+	// Attempts is the number of times the initialization was attempted.
+	Attempts int
+	// Err is the error of the last attempt.
+	Err error
+}
+
+func (e *InitError) Error() string {
+	if e.Attempts > 1 {
+		return fmt.Sprintf("%s failed after %d attempts - %v", e.Step, e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("%s failed - %v", e.Step, e.Err)
+}
+
+// init runs the initialization sequence of the controller.
+func (d *Device) init(timing Timing) *InitError {
+	if err := d.t.Init(); err != nil {
+		return &InitError{Step: "wake up", Err: err}
+	}
+
+	functionSet := byte(lcdFunctionSet)
+	if d.rows > 1 {
+		functionSet |= lcd2Line
+	}
+	if err := d.command(functionSet); err != nil {
+		return &InitError{Step: "function set", Err: err}
+	}
+
+	time.Sleep(timing.Step)
+	if err := d.command(lcdDisplayControl | d.displayControl); err != nil {
+		return &InitError{Step: "display control", Err: err}
+	}
+
+	time.Sleep(timing.Step)
+	if err := d.Clear(); err != nil {
+		return &InitError{Step: "clear", Err: err}
+	}
+
+	if err := d.command(lcdEntryModeSet | d.entryMode); err != nil {
+		return &InitError{Step: "entry mode", Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package hd44780
+
+import (
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func TestNewErrors(t *testing.T) {
+	steps := []string{"function set", "display control", "clear", "entry mode"}
+	for i, step := range steps {
+		bus := &devicetest.I2C{}
+		tr, err := OpenJHD1313(bus)
+		if err != nil {
+			t.Fatal(err)
+		}
+		bus.Fail(i, nil)
+		_, err = NewWithTiming(tr, 16, 2, Timing{})
+		ierr, ok := err.(*InitError)
+		if !ok {
+			t.Errorf("%s: got error %v, want an *InitError", step, err)
+			continue
+		}
+		if ierr.Step != step || ierr.Err != devicetest.ErrInjected || ierr.Attempts != 1 {
+			t.Errorf("got %+v, want step %q failing with the injected error", ierr, step)
+		}
+	}
+
+	bus := &devicetest.I2C{}
+	tr, err := OpenPCF8574(bus, PCF8574Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Fail(0, nil)
+	_, err = NewWithTiming(tr, 20, 4, Timing{})
+	if ierr, ok := err.(*InitError); !ok || ierr.Step != "wake up" {
+		t.Errorf("got %v, want the wake up to fail", err)
+	}
+}
+
+func TestNewRetry(t *testing.T) {
+	bus := &devicetest.I2C{}
+	tr, err := OpenJHD1313(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first function set is NACKed on cold boots.
+	bus.Fail(0, nil)
+	if _, err := NewWithTiming(tr, 16, 2, Timing{Retries: 1}); err != nil {
+		t.Fatal(err)
+	}
+	want := "80 28\n80 28\n80 0c\n80 01\n80 06"
+	if got := stream(bus.Writes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+
+	// Every attempt fails.
+	bus.Reset()
+	for i := 0; i < 3; i++ {
+		bus.Fail(i*2+1, nil)
+	}
+	_, err = NewWithTiming(tr, 16, 2, Timing{Retries: 2})
+	ierr, ok := err.(*InitError)
+	if !ok || ierr.Step != "display control" || ierr.Attempts != 3 {
+		t.Fatalf("got %v, want the display control to fail 3 times", err)
+	}
+	if want := "display control failed after 3 attempts - " + devicetest.ErrInjected.Error(); ierr.Error() != want {
+		t.Errorf("got message %q, want %q", ierr.Error(), want)
+	}
+}
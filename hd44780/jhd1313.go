@@ -0,0 +1,52 @@
+package hd44780
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/io/i2c"
+	"golang.org/x/exp/io/i2c/driver"
+)
+
+// JHD1313Addr is the I2C address of the JHD1313 controller.
+const JHD1313Addr = 0x3E
+
+// JHD1313 control bytes, sent before each instruction or data.
+const (
+	jhdCmd  = 0x80
+	jhdData = 0x40
+)
+
+// JHD1313 is the transport of the JHD1313 controllers found on the Grove
+// LCDs, which expose the HD44780 instruction set over I2C.
+type JHD1313 struct {
+	Device *i2c.Device
+}
+
+// OpenJHD1313 opens the JHD1313 controller.
+func OpenJHD1313(o driver.Opener) (*JHD1313, error) {
+	device, err := i2c.Open(o, JHD1313Addr)
+	if err != nil {
+		return nil, fmt.Errorf("LCD driver failed to connect - %v", err)
+	}
+	return &JHD1313{Device: device}, nil
+}
+
+// Init implements Transport, the controller needs no preparation.
+func (t *JHD1313) Init() error {
+	return nil
+}
+
+// Command implements Transport.
+func (t *JHD1313) Command(cmd byte) error {
+	return t.Device.Write([]byte{jhdCmd, cmd})
+}
+
+// Data implements Transport, the data is sent in a single transaction.
+func (t *JHD1313) Data(data ...byte) error {
+	return t.Device.Write(append([]byte{jhdData}, data...))
+}
+
+// Close implements Transport.
+func (t *JHD1313) Close() error {
+	return t.Device.Close()
+}
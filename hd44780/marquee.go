@@ -0,0 +1,124 @@
+package hd44780
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// MarqueeMode defines how a Marquee scrolls its text.
+type MarqueeMode int
+
+const (
+	// Loop scrolls the text to the left and wraps it around, like a ticker.
+	Loop MarqueeMode = iota
+	// Bounce scrolls the text to the left until its end is visible and
+	// then back to the right.
+	Bounce
+)
+
+// DefaultMarqueeStep is the scrolling step used when Marquee.Step is zero.
+const DefaultMarqueeStep = 300 * time.Millisecond
+
+// Marquee scrolls a text longer than the row on a single row of a Screen,
+// the other rows are left untouched. Texts that fit on the row are
+// displayed without scrolling.
+type Marquee struct {
+	// Text is the text to display.
+	Text string
+	// Row is the row the text is displayed on, starting at 0.
+	Row int
+	// Mode defines how the text scrolls, the default is Loop.
+	Mode MarqueeMode
+	// Step is the time between two one character shifts, it defines the
+	// scrolling speed. DefaultMarqueeStep is used if zero.
+	Step time.Duration
+	// Pause is the extra time spent at the ends of the text: when the
+	// text starts in Loop mode, and at both ends in Bounce mode.
+	Pause time.Duration
+	// Separator is inserted between the end and the start of the text in
+	// Loop mode. Three spaces are used if empty.
+	Separator string
+}
+
+// Run scrolls the text until the context is done and returns the context
+// error, or the first error reported by the display. Run blocks, it is
+// usually called in its own goroutine:
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	m := &hd44780.Marquee{Text: "a text too long for the display", Row: 1}
+//	go m.Run(ctx, screen)
+func (m *Marquee) Run(ctx context.Context, s *Screen) error {
+	if m.Row < 0 || m.Row >= s.d.rows {
+		return ErrInvalidPosition
+	}
+	step := m.Step
+	if step == 0 {
+		step = DefaultMarqueeStep
+	}
+
+	frames, pauses := m.frames(s.d.cols)
+	for i := 0; ; i = (i + 1) % len(frames) {
+		if err := s.SetLine(m.Row, frames[i]); err != nil {
+			return err
+		}
+		if err := s.Flush(); err != nil {
+			return err
+		}
+		if len(frames) == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		d := step
+		if pauses[i] {
+			d += m.Pause
+		}
+		t := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// frames returns a full cycle of the content of a row of cols characters
+// and whether to pause after each frame.
+func (m *Marquee) frames(cols int) ([]string, []bool) {
+	text := []rune(m.Text)
+	if len(text) <= cols {
+		return []string{m.Text}, []bool{false}
+	}
+
+	var frames []string
+	var pauses []bool
+	switch m.Mode {
+	case Bounce:
+		last := len(text) - cols
+		for off := 0; off <= last; off++ {
+			frames = append(frames, string(text[off:off+cols]))
+			pauses = append(pauses, off == 0 || off == last)
+		}
+		for off := last - 1; off > 0; off-- {
+			frames = append(frames, string(text[off:off+cols]))
+			pauses = append(pauses, false)
+		}
+	default:
+		sep := m.Separator
+		if sep == "" {
+			sep = strings.Repeat(" ", 3)
+		}
+		cycle := append(text, []rune(sep)...)
+		for off := range cycle {
+			var frame []rune
+			for i := 0; i < cols; i++ {
+				frame = append(frame, cycle[(off+i)%len(cycle)])
+			}
+			frames = append(frames, string(frame))
+			pauses = append(pauses, off == 0)
+		}
+	}
+	return frames, pauses
+}
@@ -0,0 +1,132 @@
+package hd44780
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMarqueeFrames(t *testing.T) {
+	tests := []struct {
+		m      Marquee
+		frames []string
+		pauses []bool
+	}{
+		{
+			m:      Marquee{Text: "short"},
+			frames: []string{"short"},
+			pauses: []bool{false},
+		},
+		{
+			m: Marquee{Text: "0123456789abcdefgh", Separator: "|"},
+			frames: []string{
+				"0123456789abcdef",
+				"123456789abcdefg",
+				"23456789abcdefgh",
+				"3456789abcdefgh|",
+				"456789abcdefgh|0",
+				"56789abcdefgh|01",
+				"6789abcdefgh|012",
+				"789abcdefgh|0123",
+				"89abcdefgh|01234",
+				"9abcdefgh|012345",
+				"abcdefgh|0123456",
+				"bcdefgh|01234567",
+				"cdefgh|012345678",
+				"defgh|0123456789",
+				"efgh|0123456789a",
+				"fgh|0123456789ab",
+				"gh|0123456789abc",
+				"h|0123456789abcd",
+				"|0123456789abcde",
+			},
+			pauses: []bool{
+				true, false, false, false, false, false, false, false, false, false,
+				false, false, false, false, false, false, false, false, false,
+			},
+		},
+		{
+			m: Marquee{Text: "0123456789abcdefgh", Mode: Bounce},
+			frames: []string{
+				"0123456789abcdef",
+				"123456789abcdefg",
+				"23456789abcdefgh",
+				"123456789abcdefg",
+			},
+			pauses: []bool{true, false, true, false},
+		},
+		{
+			m: Marquee{Text: "çà et là, très loin", Mode: Bounce},
+			frames: []string{
+				"çà et là, très l",
+				"à et là, très lo",
+				" et là, très loi",
+				"et là, très loin",
+				" et là, très loi",
+				"à et là, très lo",
+			},
+			pauses: []bool{true, false, false, true, false, false},
+		},
+	}
+	for _, tt := range tests {
+		frames, pauses := tt.m.frames(16)
+		if !reflect.DeepEqual(frames, tt.frames) {
+			t.Errorf("%q: got frames %q, want %q", tt.m.Text, frames, tt.frames)
+		}
+		if !reflect.DeepEqual(pauses, tt.pauses) {
+			t.Errorf("%q: got pauses %v, want %v", tt.m.Text, pauses, tt.pauses)
+		}
+	}
+}
+
+func TestMarqueeRun(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	s.SetLine(0, "static")
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &Marquee{Text: "a text too long for the display", Row: 1, Step: time.Millisecond}
+	done := make(chan error)
+	go func() { done <- m.Run(ctx, s) }()
+
+	// wait for a few frames
+	deadline := time.Now().Add(time.Second)
+	for s.Line(1) == "a text too long " || s.Line(1) == "                " {
+		if time.Now().After(deadline) {
+			t.Fatal("the text didn't scroll")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("got = %v, want = %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after the context was canceled")
+	}
+
+	if got := s.Line(0); got != "static          " {
+		t.Errorf("first row changed to %q", got)
+	}
+	// Only the second row was updated.
+	for _, w := range bus.Writes() {
+		if w[0] == jhdCmd && w[1]&lcdSetDdramAddr != 0 && w[1]&lcd2ndLineOffset == 0 {
+			t.Fatalf("the cursor moved to the first row: % x", w)
+		}
+	}
+}
+
+func TestMarqueeInvalidRow(t *testing.T) {
+	d, _ := openDisplay(t)
+	m := &Marquee{Text: "x", Row: 2}
+	if err := m.Run(context.Background(), NewScreen(d)); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+}
@@ -0,0 +1,98 @@
+package hd44780
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/io/i2c"
+	"golang.org/x/exp/io/i2c/driver"
+)
+
+// PCF8574Addr is the default I2C address of the PCF8574 backpacks, the
+// ones based on the PCF8574A usually use 0x3F.
+const PCF8574Addr = 0x27
+
+// PCF8574 port bits, as wired on most backpacks. The data lines D4 to D7
+// of the controller are connected to P4 to P7.
+const (
+	pcfRS        = 0x01 // register select, set for data
+	pcfRW        = 0x02 // read/write, always cleared
+	pcfEN        = 0x04 // enable, data is latched on the falling edge
+	pcfBacklight = 0x08
+)
+
+// PCF8574 is the transport of the I2C backpacks based on the PCF8574 I/O
+// expander, which drive the controller in 4-bit mode.
+type PCF8574 struct {
+	Device *i2c.Device
+
+	backlight byte
+}
+
+// OpenPCF8574 opens the backpack at the I2C address addr, usually
+// PCF8574Addr or 0x3F. The backlight is turned on.
+func OpenPCF8574(o driver.Opener, addr int) (*PCF8574, error) {
+	device, err := i2c.Open(o, addr)
+	if err != nil {
+		return nil, fmt.Errorf("LCD backpack failed to connect - %v", err)
+	}
+	return &PCF8574{Device: device, backlight: pcfBacklight}, nil
+}
+
+// Init implements Transport, it switches the controller to 4-bit mode
+// whatever mode it was in, following the initialization by instruction
+// sequence of the datasheet.
+func (t *PCF8574) Init() error {
+	for _, wait := range []time.Duration{5 * time.Millisecond, time.Millisecond, time.Millisecond} {
+		if err := t.Device.Write(t.nibble(0x03, 0)); err != nil {
+			return err
+		}
+		time.Sleep(wait)
+	}
+	return t.Device.Write(t.nibble(0x02, 0))
+}
+
+// Command implements Transport.
+func (t *PCF8574) Command(cmd byte) error {
+	return t.Device.Write(t.encode(cmd, 0))
+}
+
+// Data implements Transport, the data is sent in a single transaction.
+func (t *PCF8574) Data(data ...byte) error {
+	buf := make([]byte, 0, 4*len(data))
+	for _, b := range data {
+		buf = append(buf, t.encode(b, pcfRS)...)
+	}
+	return t.Device.Write(buf)
+}
+
+// SetBacklight turns the backlight on or off.
+func (t *PCF8574) SetBacklight(on bool) error {
+	var v byte
+	if on {
+		v = pcfBacklight
+	}
+	if err := t.Device.Write([]byte{v}); err != nil {
+		return err
+	}
+	t.backlight = v
+	return nil
+}
+
+// Close implements Transport.
+func (t *PCF8574) Close() error {
+	return t.Device.Close()
+}
+
+// encode returns the port values sending b as two nibbles, high nibble
+// first.
+func (t *PCF8574) encode(b, flags byte) []byte {
+	return append(t.nibble(b>>4, flags), t.nibble(b&0x0f, flags)...)
+}
+
+// nibble returns the port values sending the 4 low bits of n: the enable
+// line is pulsed with the data on D4 to D7.
+func (t *PCF8574) nibble(n, flags byte) []byte {
+	v := n<<4 | flags | t.backlight
+	return []byte{v | pcfEN, v}
+}
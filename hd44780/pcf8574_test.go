@@ -0,0 +1,48 @@
+package hd44780
+
+import (
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func TestPCF8574(t *testing.T) {
+	bus := &devicetest.I2C{}
+	tr, err := OpenPCF8574(bus, PCF8574Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := New(tr, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.SetCursor(0, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.WriteString("Hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.SetBacklight(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Home(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "pcf8574")
+}
+
+func TestPCF8574Encode(t *testing.T) {
+	tr := &PCF8574{backlight: pcfBacklight}
+	want := []byte{0x4d, 0x49, 0x8d, 0x89}
+	if got := tr.encode('H', pcfRS); string(got) != string(want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+	tr.backlight = 0
+	want = []byte{0x04, 0x00, 0x14, 0x10}
+	if got := tr.encode(lcdClearDisplay, 0); string(got) != string(want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
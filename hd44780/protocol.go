@@ -0,0 +1,168 @@
+package hd44780
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	lcdClearDisplay        = 0x01
+	lcdReturnHome          = 0x02
+	lcdEntryModeSet        = 0x04
+	lcdDisplayControl      = 0x08
+	lcdCursorShift         = 0x10
+	lcdFunctionSet         = 0x20
+	lcdSetCgramAddr        = 0x40
+	lcdSetDdramAddr        = 0x80
+	lcdEntryRight          = 0x00
+	lcdEntryLeft           = 0x02
+	lcdEntryShiftIncrement = 0x01
+	lcdEntryShiftDecrement = 0x00
+	lcdDisplayOn           = 0x04
+	lcdDisplayOff          = 0x00
+	lcdCursorOn            = 0x02
+	lcdCursorOff           = 0x00
+	lcdBlinkOn             = 0x01
+	lcdBlinkOff            = 0x00
+	lcdDisplayMove         = 0x08
+	lcdCursorMove          = 0x00
+	lcdMoveRight           = 0x04
+	lcdMoveLeft            = 0x00
+	lcd2Line               = 0x08
+
+	lcd2ndLineOffset = 0x40
+)
+
+var (
+	ErrInvalidPosition = errors.New("Invalid position value")
+
+	// ErrTooManyGlyphs is returned by Write when a message needs more
+	// custom glyphs than there are CGRAM slots free, neither reserved by
+	// SetCustomChar nor holding a glyph still shown on the display.
+	ErrTooManyGlyphs = errors.New("too many custom glyphs, the LCD only has 8 CGRAM slots")
+
+	// ErrUnknownGlyph is returned by Write when a character is neither in
+	// the controller's ROM nor in the Glyphs table.
+	ErrUnknownGlyph = errors.New("unknown glyph")
+)
+
+// rowOffsets returns the DDRAM address of the first character of each
+// row. In 2-line mode, the DDRAM holds two lines of 40 characters at 0x00
+// and 0x40. Displays of 3 or 4 rows show the second half of these lines
+// on their last rows, e.g. a 20x4 display starts its rows at 0x00, 0x40,
+// 0x14 and 0x54. In 1-line mode, the DDRAM is a single line of 80
+// characters.
+func rowOffsets(cols, rows int) ([]byte, error) {
+	maxCols := 40
+	switch {
+	case rows == 1:
+		maxCols = 80
+	case rows > 2:
+		maxCols = 20
+	}
+	if cols < 1 || cols > maxCols || rows < 1 || rows > 4 {
+		return nil, fmt.Errorf("unsupported display size %dx%d", cols, rows)
+	}
+	offsets := []byte{0x00, lcd2ndLineOffset, byte(cols), lcd2ndLineOffset + byte(cols)}
+	return offsets[:rows], nil
+}
+
+func (d *Device) command(cmd byte) error {
+	return d.t.Command(cmd)
+}
+
+// data writes a character at the current DDRAM address.
+func (d *Device) data(c byte) error {
+	if err := d.t.Data(c); err != nil {
+		return err
+	}
+	if col, row, ok := d.cell(d.addr); ok {
+		d.text[row][col] = c
+	}
+	d.advance()
+	return nil
+}
+
+// cell returns the position of the cell shown at the DDRAM address addr,
+// ok is false if the address is not shown.
+func (d *Device) cell(addr byte) (col, row int, ok bool) {
+	for row, offset := range d.offsets {
+		if addr >= offset && addr < offset+byte(d.cols) {
+			return int(addr - offset), row, true
+		}
+	}
+	return 0, 0, false
+}
+
+// setDisplayControl sets or clears flag in the display control register,
+// keeping the other flags as they are.
+func (d *Device) setDisplayControl(flag byte, on bool) error {
+	v := d.displayControl &^ flag
+	if on {
+		v |= flag
+	}
+	if err := d.command(lcdDisplayControl | v); err != nil {
+		return err
+	}
+	d.displayControl = v
+	return nil
+}
+
+// setEntryMode sets or clears flag in the entry mode register, keeping
+// the other flags as they are.
+func (d *Device) setEntryMode(flag byte, on bool) error {
+	v := d.entryMode &^ flag
+	if on {
+		v |= flag
+	}
+	if err := d.command(lcdEntryModeSet | v); err != nil {
+		return err
+	}
+	d.entryMode = v
+	return nil
+}
+
+// loadGlyph writes charMap into the CGRAM slot pos. The address counter
+// is left in CGRAM, see restoreAddr.
+func (d *Device) loadGlyph(pos int, charMap [8]byte) error {
+	location := uint8(pos)
+	if err := d.command(lcdSetCgramAddr | (location << 3)); err != nil {
+		return err
+	}
+	return d.t.Data(charMap[:]...)
+}
+
+// restoreAddr points the address counter back to the current DDRAM address.
+func (d *Device) restoreAddr() error {
+	return d.command(lcdSetDdramAddr | d.addr)
+}
+
+// advance moves the tracked DDRAM address like the controller does after
+// a character was written. Each line is 40 characters long in 2-line mode
+// and 80 characters long in 1-line mode.
+func (d *Device) advance() {
+	if d.rows == 1 {
+		if d.entryMode&lcdEntryLeft != 0 {
+			d.addr = (d.addr + 1) % 80
+		} else {
+			d.addr = (d.addr + 79) % 80
+		}
+		return
+	}
+
+	line := d.addr & lcd2ndLineOffset
+	col := d.addr &^ lcd2ndLineOffset
+	if d.entryMode&lcdEntryLeft != 0 {
+		if col++; col == 40 {
+			col = 0
+			line ^= lcd2ndLineOffset
+		}
+	} else {
+		if col == 0 {
+			col = 40
+			line ^= lcd2ndLineOffset
+		}
+		col--
+	}
+	d.addr = line | col
+}
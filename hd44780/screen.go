@@ -0,0 +1,173 @@
+package hd44780
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Screen is an in-memory model of the display. Callers set lines or
+// cells and Flush sends only the cells that changed since the previous
+// Flush, which is faster and doesn't flicker like clearing and rewriting
+// the whole display.
+//
+// Flush assumes that nothing else writes to the display: call Invalidate
+// after using the display directly, the next Flush then redraws every
+// cell. The display must be in the default left to right entry mode.
+// A Screen can be used from several goroutines, for instance while a
+// Marquee runs on one of the rows.
+type Screen struct {
+	mu    sync.Mutex
+	d     *Device
+	cells [][]rune
+	shown [][]int // codes on the display, -1 if unknown
+}
+
+// NewScreen returns a blank screen model for the display d.
+// The first Flush draws every cell.
+func NewScreen(d *Device) *Screen {
+	s := &Screen{
+		d:     d,
+		cells: make([][]rune, d.rows),
+		shown: make([][]int, d.rows),
+	}
+	for row := range s.cells {
+		s.cells[row] = make([]rune, d.cols)
+		s.shown[row] = make([]int, d.cols)
+	}
+	s.Clear()
+	s.Invalidate()
+	return s
+}
+
+// Clear blanks the screen model. Call Flush to update the display.
+func (s *Screen) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for row := range s.cells {
+		for col := range s.cells[row] {
+			s.cells[row][col] = ' '
+		}
+	}
+}
+
+// Invalidate forgets what is shown on the display so that the next Flush
+// redraws every cell.
+func (s *Screen) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for row := range s.shown {
+		for col := range s.shown[row] {
+			s.shown[row][col] = -1
+		}
+	}
+}
+
+// SetLine sets the text of the row. Text longer than the row is
+// truncated, shorter text is padded with spaces. Control characters are
+// replaced with spaces, a cell shows a single character.
+// Call Flush to update the display.
+func (s *Screen) SetLine(row int, text string) error {
+	if row < 0 || row >= s.d.rows {
+		return ErrInvalidPosition
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for col := range s.cells[row] {
+		r := ' '
+		if text != "" {
+			var n int
+			r, n = utf8.DecodeRuneInString(text)
+			text = text[n:]
+		}
+		s.cells[row][col] = cellRune(r)
+	}
+	return nil
+}
+
+// SetCell sets the character at column col of the row, both starting at 0.
+// A control character is replaced with a space.
+// Call Flush to update the display.
+func (s *Screen) SetCell(col, row int, r rune) error {
+	if col < 0 || col >= s.d.cols || row < 0 || row >= s.d.rows {
+		return ErrInvalidPosition
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cells[row][col] = cellRune(r)
+	return nil
+}
+
+// cellRune returns the character shown in a cell for r, control
+// characters having no glyph.
+func cellRune(r rune) rune {
+	if unicode.IsControl(r) {
+		return ' '
+	}
+	return r
+}
+
+// Cell returns the character at column col of the row.
+func (s *Screen) Cell(col, row int) rune {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cells[row][col]
+}
+
+// Line returns the text of the row, including the padding spaces.
+func (s *Screen) Line(row int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.line(row)
+}
+
+func (s *Screen) line(row int) string {
+	return string(s.cells[row])
+}
+
+// Flush sends the changed cells to the display. Runs of changed cells are
+// written in a row and the cursor only jumps over unchanged cells, a
+// single unchanged cell is rewritten since it costs as much as a jump.
+func (s *Screen) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Map the whole screen at once so that the glyphs of every cell
+	// stay in CGRAM. The cells shown before don't matter, the changed
+	// ones are all rewritten.
+	lines := make([]string, s.d.rows)
+	for row := range lines {
+		lines[row] = s.line(row)
+	}
+	codes, err := s.d.mapRunes(strings.Join(lines, "\n"), false)
+	if err != nil {
+		return err
+	}
+
+	cols := s.d.cols
+	for row := 0; row < s.d.rows; row++ {
+		line := codes[row*(cols+1) : row*(cols+1)+cols]
+		for col := 0; col < cols; col++ {
+			if line[col] == s.shown[row][col] {
+				continue
+			}
+			addr := s.d.offsets[row] + byte(col)
+			if s.d.addr != addr {
+				// bridge a single unchanged cell instead of jumping over it
+				if col > 0 && s.d.addr == addr-1 {
+					col--
+				} else if err := s.d.SetCursor(col, row); err != nil {
+					return err
+				}
+			}
+			if err := s.d.data(byte(line[col])); err != nil {
+				// the cell may or may not have been updated
+				s.shown[row][col] = -1
+				return err
+			}
+			s.shown[row][col] = line[col]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package hd44780
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stream formats the writes to the controller, one per line.
+func stream(w [][]byte) string {
+	var lines []string
+	for _, b := range w {
+		lines = append(lines, fmt.Sprintf("% x", b))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestScreenFirstFlush(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	s.SetLine(0, "Hello")
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "screen_first_flush")
+
+	// Nothing changed, nothing to send.
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("got %d transactions, want 0", n)
+	}
+}
+
+func TestScreenFlushDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(s *Screen)
+		want   []string
+	}{
+		{
+			name:   "single cell",
+			update: func(s *Screen) { s.SetCell(1, 0, 'a') },
+			want:   []string{"80 81", "40 61"},
+		},
+		{
+			name: "run of cells",
+			update: func(s *Screen) {
+				s.SetLine(1, "  xyz")
+			},
+			want: []string{"80 c2", "40 78", "40 79", "40 7a"},
+		},
+		{
+			name: "single unchanged cell is rewritten",
+			update: func(s *Screen) {
+				s.SetCell(3, 0, 'L')
+				s.SetCell(5, 0, '!')
+			},
+			want: []string{"80 83", "40 4c", "40 6f", "40 21"},
+		},
+		{
+			name: "jump over unchanged cells",
+			update: func(s *Screen) {
+				s.SetCell(0, 0, 'J')
+				s.SetCell(15, 0, '>')
+				s.SetCell(0, 1, '<')
+			},
+			want: []string{"80 80", "40 4a", "80 8f", "40 3e", "80 c0", "40 3c"},
+		},
+		{
+			name: "continue after the previous write",
+			update: func(s *Screen) {
+				s.SetCell(15, 0, '.')
+				s.SetCell(0, 1, '.')
+			},
+			// The cursor doesn't wrap to the second line at column 16.
+			want: []string{"80 8f", "40 2e", "80 c0", "40 2e"},
+		},
+	}
+
+	for _, tt := range tests {
+		d, bus := openDisplay(t)
+		s := NewScreen(d)
+		s.SetLine(0, "Hello")
+		if err := s.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		bus.Reset()
+		tt.update(s)
+		if err := s.Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if got, want := stream(bus.Writes()), strings.Join(tt.want, "\n"); got != want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestScreenGlyphs(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	s.SetLine(0, "café")
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	s.SetLine(1, "thé")
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// é is already in CGRAM slot 0.
+	want := "80 c0\n40 74\n40 68\n40 00"
+	if got := stream(bus.Writes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestScreenInvalidate(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s.Invalidate()
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	// 2 jumps and 32 characters.
+	if n := len(bus.Writes()); n != 34 {
+		t.Errorf("got %d transactions, want 34", n)
+	}
+}
+
+func TestScreenBounds(t *testing.T) {
+	d, _ := openDisplay(t)
+	s := NewScreen(d)
+	if err := s.SetCell(16, 0, 'x'); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+	if err := s.SetLine(2, "x"); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+	s.SetLine(0, "0123456789abcdefXYZ")
+	if got := s.Line(0); got != "0123456789abcdef" {
+		t.Errorf("got %q, want the text truncated to 16 characters", got)
+	}
+}
+
+func TestScreenControlCharacters(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetLine(0, "a\tb\x01")
+	s.SetCell(0, 1, '\n')
+	if got, want := s.Line(0), "a b             "; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got := s.Cell(0, 1); got != ' ' {
+		t.Errorf("got %q, want a space", got)
+	}
+	// Only a and b changed.
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	want := "80 80\n40 61\n40 20\n40 62"
+	if got := stream(bus.Writes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,160 @@
+package hd44780
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// tabWidth is the distance between two tab stops.
+const tabWidth = 4
+
+// isControl reports whether r is one of the control characters handled by
+// Write. The other ones are written as is, codes 0 to 7 being the CGRAM
+// characters.
+func isControl(r rune) bool {
+	switch r {
+	case '\b', '\t', '\n', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+// Write implements io.Writer, p is UTF-8 text displayed like on a
+// terminal:
+//
+//   - the text wraps at the end of the rows,
+//   - '\n' moves the cursor to the beginning of the next row,
+//   - '\r' moves the cursor to the beginning of the row,
+//   - '\b' moves the cursor one character back,
+//   - '\t' moves the cursor to the next tab stop, every 4 characters,
+//   - '\f' clears the display.
+//
+// Going past the last row scrolls the rows up if ScrollLines is set, the
+// text continues on the first row otherwise. Write expects the default
+// left to right entry mode.
+//
+// Characters missing from the controller's ROM are drawn with the glyphs
+// of the Glyphs table, which are loaded into the 8 CGRAM slots as needed,
+// evicting the least recently used ones. The glyphs still shown on the
+// display keep their slot, Clear frees them. Write fails with
+// ErrTooManyGlyphs if p and the display need more than 8 custom glyphs
+// and with ErrUnknownGlyph if a character has no glyph at all, in which
+// case nothing is written.
+func (d *Device) Write(p []byte) (n int, err error) {
+	message := string(p)
+	codes, err := d.mapRunes(message, true)
+	if err != nil {
+		return 0, err
+	}
+
+	// This wait fixes an odd bug where the clear function doesn't always work properly.
+	time.Sleep(1 * time.Millisecond)
+	for _, c := range codes {
+		r, size := utf8.DecodeRuneInString(message[n:])
+		if c < 0 {
+			err = d.control(r)
+		} else {
+			err = d.put(byte(c))
+		}
+		if err != nil {
+			return n, err
+		}
+		n += size
+	}
+	return n, nil
+}
+
+// WriteString is like Write but writes the contents of the string s.
+func (d *Device) WriteString(s string) (n int, err error) {
+	return d.Write([]byte(s))
+}
+
+// put writes c at the cursor position and moves the cursor.
+func (d *Device) put(c byte) error {
+	if d.wrap {
+		if err := d.newLine(); err != nil {
+			return err
+		}
+	}
+	if addr := d.offsets[d.row] + byte(d.col); d.addr != addr {
+		if err := d.SetCursor(d.col, d.row); err != nil {
+			return err
+		}
+	}
+	if err := d.data(c); err != nil {
+		return err
+	}
+	if d.col == d.cols-1 {
+		// wait for the next character to wrap, like terminals do, so
+		// that filling the last row doesn't scroll
+		d.wrap = true
+	} else {
+		d.col++
+	}
+	return nil
+}
+
+// control handles the control character r.
+func (d *Device) control(r rune) error {
+	switch r {
+	case '\n':
+		return d.newLine()
+	case '\r':
+		return d.SetCursor(0, d.row)
+	case '\b':
+		if d.wrap || d.col == 0 {
+			return d.SetCursor(d.col, d.row)
+		}
+		return d.SetCursor(d.col-1, d.row)
+	case '\t':
+		col := (d.col/tabWidth + 1) * tabWidth
+		if col >= d.cols {
+			col = d.cols - 1
+		}
+		return d.SetCursor(col, d.row)
+	case '\f':
+		return d.Clear()
+	}
+	return nil
+}
+
+// newLine moves the cursor to the beginning of the next row, scrolling
+// the rows up if ScrollLines is set.
+func (d *Device) newLine() error {
+	if d.row < d.rows-1 {
+		return d.SetCursor(0, d.row+1)
+	}
+	if !d.ScrollLines {
+		return d.SetCursor(0, 0)
+	}
+	return d.scrollUp()
+}
+
+// scrollUp moves the text of each row to the previous one, blanks the
+// last row and moves the cursor to its beginning.
+func (d *Device) scrollUp() error {
+	last := d.rows - 1
+	for row := 0; row < last; row++ {
+		copy(d.text[row], d.text[row+1])
+	}
+	for col := range d.text[last] {
+		d.text[last][col] = ' '
+	}
+	for row := range d.text {
+		if err := d.SetCursor(0, row); err != nil {
+			return err
+		}
+		// data updates d.text with the same values
+		for _, c := range append([]byte(nil), d.text[row]...) {
+			if err := d.data(c); err != nil {
+				return err
+			}
+		}
+	}
+	return d.SetCursor(0, last)
+}
+
+// home moves the cursor of Write to the first cell.
+func (d *Device) home() {
+	d.col, d.row, d.wrap = 0, 0, false
+}
@@ -0,0 +1,113 @@
+package hd44780
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+// shown returns the text shown on each row.
+func shown(d *Device) []string {
+	var rows []string
+	for _, row := range d.text {
+		rows = append(rows, string(row))
+	}
+	return rows
+}
+
+func checkShown(t *testing.T, d *Device, name string, want ...string) {
+	got := shown(d)
+	for row := range want {
+		if got[row] != want[row] {
+			t.Errorf("%s: row %d shows %q, want %q", name, row, got[row], want[row])
+		}
+	}
+}
+
+func TestWriteControls(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"carriage return", "hello\rJ", []string{"Jello           ", "                "}},
+		{"backspace", "ab\bc\b\b\bd", []string{"dc              ", "                "}},
+		{"tab", "a\tb\tc\t\td\te", []string{"a   b   c      e", "                "}},
+		{"form feed", "junk\nmore\fok", []string{"ok              ", "                "}},
+		{"wrap", "0123456789abcdefXYZ", []string{"0123456789abcdef", "XYZ             "}},
+		{"backspace at the end of the row", "0123456789abcdef\bX", []string{"0123456789abcdeX", "                "}},
+		{"no scroll", "one\ntwo\nthree", []string{"three           ", "two             "}},
+		{"CGRAM codes", "\x00\x07", []string{"\x00\x07              ", "                "}},
+	}
+	for _, tt := range tests {
+		d, _ := openDisplay(t)
+		n, err := d.WriteString(tt.text)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if n != len(tt.text) {
+			t.Errorf("%s: wrote %d bytes, want %d", tt.name, n, len(tt.text))
+		}
+		checkShown(t, d, tt.name, tt.want...)
+	}
+}
+
+func TestWriteScrollLines(t *testing.T) {
+	d, bus := openDisplay(t)
+	d.ScrollLines = true
+	// Filling the last row doesn't scroll.
+	if _, err := d.WriteString("one\n0123456789abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	checkShown(t, d, "filled", "one             ", "0123456789abcdef")
+
+	bus.Reset()
+	if _, err := d.WriteString("\nthree"); err != nil {
+		t.Fatal(err)
+	}
+	checkShown(t, d, "scrolled", "0123456789abcdef", "three           ")
+	bus.CheckGolden(t, "scroll")
+
+	if _, err := d.WriteString("0123456789abcdef"); err != nil {
+		t.Fatal(err)
+	}
+	checkShown(t, d, "wrapped", "three0123456789a", "bcdef           ")
+}
+
+func TestWrite20x4(t *testing.T) {
+	d, bus := openSize(t, 20, 4)
+	bus.Reset()
+	// The text continues on the second row, not on the third one which
+	// follows the first one in DDRAM.
+	if _, err := fmt.Fprintf(d, "%020d%s", 0, "ab"); err != nil {
+		t.Fatal(err)
+	}
+	checkShown(t, d, "20x4", "00000000000000000000", "ab                  ", "                    ")
+	w := bus.Writes()
+	if got, want := w[20], []byte{jhdCmd, lcdSetDdramAddr | 0x40}; string(got) != string(want) {
+		t.Errorf("got % x, want % x", got, want)
+	}
+}
+
+func TestWriteFprintf(t *testing.T) {
+	d, _ := openDisplay(t)
+	var w io.Writer = d
+	if _, err := fmt.Fprintf(w, "T=%d°C\nRH=%d%%", 21, 40); err != nil {
+		t.Fatal(err)
+	}
+	checkShown(t, d, "fprintf", "T=21\xdfC          ", "RH=40%          ")
+}
+
+func TestWriteError(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	bus.Fail(4, nil)
+	n, err := d.WriteString("é\nab")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	// é is loaded into CGRAM and written, the new line fails.
+	if want := len("é"); n != want {
+		t.Errorf("wrote %d bytes, want %d", n, want)
+	}
+}
@@ -0,0 +1,323 @@
+package hd44780
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+	"unicode/utf8"
+)
+
+// The widgets draw on a Screen, call Flush to update the display. They use
+// the block glyphs of CustomChars, which must stay in the Glyphs table of
+// the display.
+
+// hbarBlocks are the horizontal bar cells, indexed by the number of
+// filled columns.
+var hbarBlocks = []rune{' ', '▏', '▎', '▍', '▌', '█'}
+
+// vbarBlocks are the vertical bar cells, indexed by the number of filled
+// rows.
+var vbarBlocks = []rune{' ', '▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// HBar is a horizontal progress bar filling Width cells from the cell at
+// column Col of the row Row. Each cell is 5 pixels wide.
+type HBar struct {
+	Col, Row int
+	Width    int
+}
+
+// Draw draws the bar filled to the fraction v, between 0 and 1.
+func (b *HBar) Draw(s *Screen, v float64) error {
+	cells, err := barCells(b.Width, v, hbarBlocks)
+	if err != nil {
+		return err
+	}
+	for i, r := range cells {
+		if err := s.SetCell(b.Col+i, b.Row, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VBar is a vertical progress bar filling Height cells up from the cell
+// at column Col of the row Row. Each cell is 8 pixels high.
+type VBar struct {
+	Col, Row int
+	Height   int
+}
+
+// Draw draws the bar filled to the fraction v, between 0 and 1.
+func (b *VBar) Draw(s *Screen, v float64) error {
+	cells, err := barCells(b.Height, v, vbarBlocks)
+	if err != nil {
+		return err
+	}
+	for i, r := range cells {
+		if err := s.SetCell(b.Col, b.Row-i, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// barCells returns the n cells of a bar filled to the fraction v, from
+// its origin. blocks are the cells indexed by their number of pixels.
+func barCells(n int, v float64, blocks []rune) ([]rune, error) {
+	size := len(blocks) - 1
+	if n < 1 {
+		return nil, fmt.Errorf("invalid bar length: %d", n)
+	}
+	v = math.Max(0, math.Min(1, v))
+	filled := int(math.Floor(v*float64(n*size) + 0.5))
+	cells := make([]rune, n)
+	for i := range cells {
+		px := filled - i*size
+		switch {
+		case px >= size:
+			px = size
+		case px < 0:
+			px = 0
+		}
+		cells[i] = blocks[px]
+	}
+	return cells, nil
+}
+
+// bigDigits are the two rows of the big digits, 3 cells wide.
+var bigDigits = map[rune][2]string{
+	'0': {"█⎺█", "█⎽█"},
+	'1': {"⎺█ ", "⎽█⎽"},
+	'2': {"≡≡█", "█⎽⎽"},
+	'3': {"≡≡█", "⎽⎽█"},
+	'4': {"█⎽█", "  █"},
+	'5': {"█≡≡", "⎽⎽█"},
+	'6': {"█≡≡", "█⎽█"},
+	'7': {"⎺⎺█", "  █"},
+	'8': {"█≡█", "█⎽█"},
+	'9': {"█≡█", "⎽⎽█"},
+	'-': {"⎽⎽⎽", "   "},
+	' ': {"   ", "   "},
+	':': {".", "."},
+	'.': {" ", "."},
+}
+
+// BigNumber draws numbers two rows high, from the cell at column Col of
+// the row Row. Digits are 3 cells wide and separated by a blank cell.
+type BigNumber struct {
+	Col, Row int
+}
+
+// Width returns the number of cells used to draw text.
+func (n *BigNumber) Width(text string) int {
+	w := 0
+	for _, r := range text {
+		if w > 0 {
+			w++
+		}
+		w += utf8.RuneCountInString(bigDigits[r][0])
+	}
+	return w
+}
+
+// Draw draws text, which can contain digits, spaces, '-', ':' and '.'.
+// Nothing is drawn if text has other characters or doesn't fit.
+func (n *BigNumber) Draw(s *Screen, text string) error {
+	for _, r := range text {
+		if _, ok := bigDigits[r]; !ok {
+			return fmt.Errorf("can't draw %q with big digits", r)
+		}
+	}
+	if n.Col < 0 || n.Col+n.Width(text) > s.d.cols || n.Row < 0 || n.Row+1 >= s.d.rows {
+		return ErrInvalidPosition
+	}
+	col := n.Col
+	for i, r := range text {
+		if i > 0 {
+			s.SetCell(col, n.Row, ' ')
+			s.SetCell(col, n.Row+1, ' ')
+			col++
+		}
+		glyph := bigDigits[r]
+		for row, cells := range glyph {
+			for j, c := range []rune(cells) {
+				s.SetCell(col+j, n.Row+row, c)
+			}
+		}
+		col += utf8.RuneCountInString(glyph[0])
+	}
+	return nil
+}
+
+// DefaultSpinnerFrames are the frames used when Spinner.Frames is empty.
+const DefaultSpinnerFrames = "|/-╲"
+
+// DefaultSpinnerStep is the time between two frames used when
+// Spinner.Step is zero.
+const DefaultSpinnerStep = 150 * time.Millisecond
+
+// Spinner is a single cell animation showing that something is going on.
+type Spinner struct {
+	// Col and Row are the position of the spinner.
+	Col, Row int
+	// Frames are the characters shown in turn, DefaultSpinnerFrames is
+	// used if empty.
+	Frames string
+	// Step is the time between two frames, DefaultSpinnerStep is used
+	// if zero.
+	Step time.Duration
+
+	frame int
+}
+
+// Tick draws the next frame.
+func (sp *Spinner) Tick(s *Screen) error {
+	frames := []rune(sp.Frames)
+	if len(frames) == 0 {
+		frames = []rune(DefaultSpinnerFrames)
+	}
+	sp.frame %= len(frames)
+	if err := s.SetCell(sp.Col, sp.Row, frames[sp.frame]); err != nil {
+		return err
+	}
+	sp.frame++
+	return nil
+}
+
+// Run animates the spinner until the context is done and returns the
+// context error, or the first error reported by the display. Like
+// Marquee.Run, Run blocks.
+func (sp *Spinner) Run(ctx context.Context, s *Screen) error {
+	step := sp.Step
+	if step == 0 {
+		step = DefaultSpinnerStep
+	}
+	t := time.NewTicker(step)
+	defer t.Stop()
+	for {
+		if err := sp.Tick(s); err != nil {
+			return err
+		}
+		if err := s.Flush(); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// MenuEvent is an input event driving a Menu.
+type MenuEvent int
+
+const (
+	// MenuUp moves the cursor to the previous item.
+	MenuUp MenuEvent = iota
+	// MenuDown moves the cursor to the next item.
+	MenuDown
+	// MenuSelect selects the item under the cursor.
+	MenuSelect
+)
+
+// Menu is a scrollable list of items with a cursor. It is driven by
+// events, from buttons or a rotary encoder for instance, and drawn on
+// Height rows of a Screen starting at the row Row, the cursor marker in
+// the first column.
+type Menu struct {
+	// Items are the menu entries.
+	Items []string
+	// Row is the first row used by the menu.
+	Row int
+	// Height is the number of rows used by the menu, 0 means every row
+	// from Row to the last one.
+	Height int
+	// Marker is shown in front of the item under the cursor, '→' is
+	// used if zero.
+	Marker rune
+	// Wrap makes the cursor go from the last item to the first one and
+	// back.
+	Wrap bool
+
+	cursor int
+	top    int // first item shown
+}
+
+// Cursor returns the index of the item under the cursor.
+func (m *Menu) Cursor() int {
+	return m.cursor
+}
+
+// SetCursor moves the cursor to the item i.
+func (m *Menu) SetCursor(i int) error {
+	if i < 0 || i >= len(m.Items) {
+		return fmt.Errorf("invalid menu item: %d", i)
+	}
+	m.cursor = i
+	return nil
+}
+
+// Handle updates the menu with the event. It returns the index of the
+// selected item and true on MenuSelect.
+func (m *Menu) Handle(e MenuEvent) (int, bool) {
+	n := len(m.Items)
+	if n == 0 {
+		return 0, false
+	}
+	switch e {
+	case MenuUp:
+		if m.cursor > 0 {
+			m.cursor--
+		} else if m.Wrap {
+			m.cursor = n - 1
+		}
+	case MenuDown:
+		if m.cursor < n-1 {
+			m.cursor++
+		} else if m.Wrap {
+			m.cursor = 0
+		}
+	case MenuSelect:
+		return m.cursor, true
+	}
+	return 0, false
+}
+
+// Draw draws the items around the cursor, scrolling the list to keep the
+// cursor visible. Items longer than the row are truncated.
+func (m *Menu) Draw(s *Screen) error {
+	height := m.Height
+	if height == 0 {
+		height = s.d.rows - m.Row
+	}
+	if m.Row < 0 || height < 1 || m.Row+height > s.d.rows {
+		return ErrInvalidPosition
+	}
+	marker := m.Marker
+	if marker == 0 {
+		marker = '→'
+	}
+
+	if m.cursor < m.top {
+		m.top = m.cursor
+	} else if m.cursor >= m.top+height {
+		m.top = m.cursor - height + 1
+	}
+	for i := 0; i < height; i++ {
+		item := m.top + i
+		line := ""
+		switch {
+		case item >= len(m.Items):
+		case item == m.cursor:
+			line = string(marker) + m.Items[item]
+		default:
+			line = " " + m.Items[item]
+		}
+		if err := s.SetLine(m.Row+i, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package hd44780
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHBar(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	b := &HBar{Col: 2, Row: 1, Width: 10}
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "                "},
+		{0.33, "  ███▎          "},
+		{0.5, "  █████         "},
+		{1, "  ██████████    "},
+		{2, "  ██████████    "},
+		{-1, "                "},
+	}
+	for _, tt := range tests {
+		if err := b.Draw(s, tt.v); err != nil {
+			t.Fatal(err)
+		}
+		if got := s.Line(1); got != tt.want {
+			t.Errorf("Draw(%v) drew %q, want %q", tt.v, got, tt.want)
+		}
+	}
+
+	if err := b.Draw(s, 0.33); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "hbar")
+
+	if err := (&HBar{Col: 10, Width: 10}).Draw(s, 1); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+}
+
+func TestVBar(t *testing.T) {
+	d, _ := openDisplay(t)
+	s := NewScreen(d)
+	b := &VBar{Col: 15, Row: 1, Height: 2}
+	tests := []struct {
+		v      float64
+		top    rune
+		bottom rune
+	}{
+		{0, ' ', ' '},
+		{0.25, ' ', '▄'},
+		{0.5, ' ', '█'},
+		{0.75, '▄', '█'},
+		{0.95, '▇', '█'},
+	}
+	for _, tt := range tests {
+		if err := b.Draw(s, tt.v); err != nil {
+			t.Fatal(err)
+		}
+		if top, bottom := s.Cell(15, 0), s.Cell(15, 1); top != tt.top || bottom != tt.bottom {
+			t.Errorf("Draw(%v) drew %q over %q, want %q over %q", tt.v, top, bottom, tt.top, tt.bottom)
+		}
+	}
+}
+
+func TestBigNumber(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	n := &BigNumber{Col: 1}
+	if err := n.Draw(s, "12:0"); err != nil {
+		t.Fatal(err)
+	}
+	want := [2]string{
+		" ⎺█  ≡≡█ . █⎺█  ",
+		" ⎽█⎽ █⎽⎽ . █⎽█  ",
+	}
+	// The last digit doesn't fit, nothing is drawn.
+	if err := n.Draw(s, "12:09"); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+	if err := n.Draw(s, "1a"); err == nil {
+		t.Error("letters should be rejected")
+	}
+	for row := range want {
+		if got := s.Line(row); got != want[row] {
+			t.Errorf("row %d: got %q, want %q", row, got, want[row])
+		}
+	}
+	if w := n.Width("12:0"); w != 13 {
+		t.Errorf("got width %d, want 13", w)
+	}
+
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "bignumber")
+}
+
+func TestSpinner(t *testing.T) {
+	d, _ := openDisplay(t)
+	s := NewScreen(d)
+	sp := &Spinner{Col: 15}
+	var got []rune
+	for i := 0; i < 5; i++ {
+		if err := sp.Tick(s); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, s.Cell(15, 0))
+	}
+	if want := "|/-╲|"; string(got) != want {
+		t.Errorf("got frames %q, want %q", string(got), want)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpinnerRun(t *testing.T) {
+	d, _ := openDisplay(t)
+	s := NewScreen(d)
+	sp := &Spinner{Row: 1, Frames: ".o", Step: time.Millisecond}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- sp.Run(ctx, s) }()
+
+	deadline := time.Now().Add(time.Second)
+	for s.Cell(0, 1) != 'o' {
+		if time.Now().After(deadline) {
+			t.Fatal("the spinner didn't spin")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got = %v, want = %v", err, context.Canceled)
+	}
+}
+
+func TestMenu(t *testing.T) {
+	d, _ := openDisplay(t)
+	s := NewScreen(d)
+	m := &Menu{Items: []string{"Start", "Settings", "Network", "About"}}
+
+	draw := func(want0, want1 string) {
+		if err := m.Draw(s); err != nil {
+			t.Fatal(err)
+		}
+		if got0, got1 := s.Line(0), s.Line(1); got0 != want0 || got1 != want1 {
+			t.Errorf("got %q, %q; want %q, %q", got0, got1, want0, want1)
+		}
+	}
+
+	draw("→Start          ", " Settings       ")
+	m.Handle(MenuUp)
+	draw("→Start          ", " Settings       ")
+	m.Handle(MenuDown)
+	draw(" Start          ", "→Settings       ")
+	m.Handle(MenuDown)
+	draw(" Settings       ", "→Network        ")
+	m.Handle(MenuDown)
+	m.Handle(MenuDown)
+	draw(" Network        ", "→About          ")
+	m.Handle(MenuUp)
+	m.Handle(MenuUp)
+	draw("→Settings       ", " Network        ")
+
+	if i, ok := m.Handle(MenuSelect); !ok || i != 1 {
+		t.Errorf("got selection %d, %v; want 1, true", i, ok)
+	}
+	if _, ok := m.Handle(MenuDown); ok {
+		t.Error("moving the cursor shouldn't select")
+	}
+
+	m.Wrap = true
+	m.Handle(MenuDown)
+	m.Handle(MenuDown)
+	if got := m.Cursor(); got != 0 {
+		t.Errorf("got cursor %d after wrapping, want 0", got)
+	}
+	m.Handle(MenuUp)
+	if got := m.Cursor(); got != 3 {
+		t.Errorf("got cursor %d after wrapping back, want 3", got)
+	}
+}
+
+func TestMenuLayout(t *testing.T) {
+	d, _ := openDisplay(t)
+	s := NewScreen(d)
+	s.SetLine(0, "Pick one:")
+	m := &Menu{Items: []string{"yes", "no"}, Row: 1, Marker: '*'}
+	m.SetCursor(1)
+	if err := m.Draw(s); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Line(0), "Pick one:       "; got != want {
+		t.Errorf("the menu overwrote the first row: %q", got)
+	}
+	if got, want := s.Line(1), "*no             "; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := m.SetCursor(2); err == nil {
+		t.Error("SetCursor should reject out of range items")
+	}
+	m.Height = 2
+	if err := m.Draw(s); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+}
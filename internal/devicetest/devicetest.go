@@ -0,0 +1,223 @@
+// Package devicetest provides fake I2C and SPI openers for testing the
+// device drivers without any hardware.
+//
+// The fakes record every transaction in a transcript, serve scripted
+// responses to reads and can be told to fail on purpose. Transcripts
+// can be compared against golden files stored in the testdata directory
+// of the package under test, run the tests with -update to rewrite them.
+package devicetest
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// ErrInjected is the error returned by the fakes when a failure was
+// requested without a specific error.
+var ErrInjected = errors.New("devicetest: injected failure")
+
+// Tx is a recorded transaction.
+type Tx struct {
+	Bus   string // Bus is either "i2c" or "spi".
+	Addr  int    // Addr is the I2C address, it is always 0 on SPI buses.
+	Write []byte // Write is the data written to the device, if any.
+	Read  []byte // Read is the data read from the device, if any.
+	Err   error  // Err is the error returned by the transaction.
+}
+
+// Recorder records the activity of fake connections. It is embedded in
+// I2C and SPI, the zero value is ready to use.
+type Recorder struct {
+	mu    sync.Mutex
+	lines []string
+	txs   []Tx
+	fails map[int]error
+}
+
+// Fail makes the nth transaction (counting from 0 since the recorder was
+// created or reset) fail with err. If err is nil, ErrInjected is used.
+// Failed transactions are recorded but don't consume scripted reads.
+func (r *Recorder) Fail(n int, err error) {
+	if err == nil {
+		err = ErrInjected
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fails == nil {
+		r.fails = make(map[int]error)
+	}
+	r.fails[n] = err
+}
+
+// Txs returns the recorded transactions.
+func (r *Recorder) Txs() []Tx {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Tx(nil), r.txs...)
+}
+
+// Writes returns the data of the write transactions, in order.
+func (r *Recorder) Writes() [][]byte {
+	var w [][]byte
+	for _, tx := range r.Txs() {
+		if tx.Write != nil {
+			w = append(w, tx.Write)
+		}
+	}
+	return w
+}
+
+// Written returns the concatenation of all the data written so far.
+func (r *Recorder) Written() []byte {
+	w := []byte{}
+	for _, tx := range r.Txs() {
+		w = append(w, tx.Write...)
+	}
+	return w
+}
+
+// Transcript returns the recorded activity in a human readable form,
+// one event per line.
+func (r *Recorder) Transcript() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.lines) == 0 {
+		return ""
+	}
+	return strings.Join(r.lines, "\n") + "\n"
+}
+
+// Reset forgets the recorded activity and the requested failures.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = nil
+	r.txs = nil
+	r.fails = nil
+}
+
+// CheckGolden compares the transcript with the testdata/name.golden file
+// and reports any difference as a test error. With the -update flag, the
+// golden file is rewritten instead.
+func (r *Recorder) CheckGolden(t testing.TB, name string) {
+	t.Helper()
+	CheckGolden(t, name, r.Transcript())
+}
+
+// CheckGolden compares got with the testdata/name.golden file and reports
+// any difference as a test error, like Recorder.CheckGolden does for
+// transcripts. It is meant for other test outputs, such as text renderings
+// of a frame buffer.
+func CheckGolden(t testing.TB, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run the tests with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("output doesn't match %s:\n%s", path, diff(string(want), got))
+	}
+}
+
+func (r *Recorder) logf(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, fmt.Sprintf(format, args...))
+}
+
+// tx records a transaction and returns the error it must fail with, if any.
+// Unless err is set or a failure was requested, reply is called to fill rd.
+func (r *Recorder) tx(bus, prefix string, addr int, w, rd []byte, err error, reply func([]byte) error) error {
+	r.mu.Lock()
+	if injected := r.fails[len(r.txs)]; err == nil {
+		err = injected
+	}
+	r.mu.Unlock()
+	if err == nil && rd != nil {
+		err = reply(rd)
+	}
+
+	tx := Tx{Bus: bus, Addr: addr, Err: err}
+	line := prefix
+	if w != nil {
+		tx.Write = append([]byte(nil), w...)
+		line += " write " + hexdump(w)
+	}
+	switch {
+	case rd != nil && err != nil:
+		line += fmt.Sprintf(" read %d bytes", len(rd))
+	case rd != nil:
+		tx.Read = append([]byte(nil), rd...)
+		line += " read " + hexdump(rd)
+	}
+	if err != nil {
+		line += " error: " + err.Error()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs = append(r.txs, tx)
+	r.lines = append(r.lines, line)
+	return err
+}
+
+// hexdump formats b as hexadecimal bytes, collapsing runs of at least
+// 8 identical bytes as value*count.
+func hexdump(b []byte) string {
+	var parts []string
+	for i := 0; i < len(b); {
+		j := i + 1
+		for j < len(b) && b[j] == b[i] {
+			j++
+		}
+		if j-i >= 8 {
+			parts = append(parts, fmt.Sprintf("%02x*%d", b[i], j-i))
+		} else {
+			for k := i; k < j; k++ {
+				parts = append(parts, fmt.Sprintf("%02x", b[k]))
+			}
+		}
+		i = j
+	}
+	return strings.Join(parts, " ")
+}
+
+// diff returns the lines of got that differ from want, prefixed by the
+// line number.
+func diff(want, got string) string {
+	wl := strings.Split(want, "\n")
+	gl := strings.Split(got, "\n")
+	var out []string
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w != g {
+			out = append(out, fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, w, g))
+		}
+	}
+	return strings.Join(out, "\n")
+}
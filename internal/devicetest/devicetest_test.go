@@ -0,0 +1,113 @@
+package devicetest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"golang.org/x/exp/io/i2c"
+	"golang.org/x/exp/io/spi"
+	"golang.org/x/exp/io/spi/driver"
+)
+
+func TestI2CTranscript(t *testing.T) {
+	bus := &I2C{}
+	bus.Reply(0x20, []byte{0xca, 0xfe})
+	dev, err := i2c.Open(bus, 0x20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Write([]byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 2)
+	if err := dev.ReadReg(0x10, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf, []byte{0xca, 0xfe}) {
+		t.Errorf("read %x, want cafe", buf)
+	}
+	if err := dev.Read(buf); err == nil {
+		t.Error("read without a scripted reply should fail")
+	}
+	if err := dev.Write(make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	dev.Close()
+
+	want := `i2c 0x20 open
+i2c 0x20 write 01 02
+i2c 0x20 write 10 read ca fe
+i2c 0x20 read 2 bytes error: no scripted reply
+i2c 0x20 write 00*10
+i2c 0x20 close
+`
+	if got := bus.Transcript(); got != want {
+		t.Errorf("transcript:\n%s\nwant:\n%s", got, want)
+	}
+	if got := len(bus.Writes()); got != 3 {
+		t.Errorf("got %d writes, want = 3", got)
+	}
+}
+
+func TestI2CFail(t *testing.T) {
+	bus := &I2C{}
+	errBoom := errors.New("boom")
+	bus.FailOpen(0x30, errBoom)
+	if _, err := i2c.Open(bus, 0x30); err != errBoom {
+		t.Errorf("open error = %v, want = %v", err, errBoom)
+	}
+
+	bus.Fail(1, nil)
+	dev, err := i2c.Open(bus, 0x20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []error{nil, ErrInjected, nil} {
+		if err := dev.Write([]byte{byte(i)}); err != want {
+			t.Errorf("write %d error = %v, want = %v", i, err, want)
+		}
+	}
+}
+
+func TestSPI(t *testing.T) {
+	bus := &SPI{MaxTxSize: 4}
+	bus.Reply([]byte{1, 2})
+	dev, err := spi.Open(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.SetMaxSpeed(1000000); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := bus.Config(driver.MaxSpeed); !ok || v != 1000000 {
+		t.Errorf("max speed = %d, %v; want 1000000", v, ok)
+	}
+	r := make([]byte, 2)
+	if err := dev.Tx([]byte{0xaa, 0xbb}, r); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Tx(make([]byte, 5), nil); err == nil {
+		t.Error("transactions longer than MaxTxSize should fail")
+	}
+
+	want := `spi open
+spi configure maxspeed 1000000
+spi write aa bb read 01 02
+spi write 00 00 00 00 00 error: message too long: limit is 4 bytes
+`
+	if got := bus.Transcript(); got != want {
+		t.Errorf("transcript:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCheckGolden(t *testing.T) {
+	bus := &SPI{}
+	dev, err := spi.Open(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dev.Tx([]byte{1, 2, 3}, nil)
+	dev.Close()
+	bus.CheckGolden(t, "spi")
+}
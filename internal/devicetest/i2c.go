@@ -0,0 +1,93 @@
+package devicetest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/io/i2c/driver"
+)
+
+// I2C is a fake I2C bus. It implements driver.Opener and records the
+// activity of every device opened on it.
+type I2C struct {
+	Recorder
+
+	mu       sync.Mutex
+	replies  map[int][][]byte
+	openErrs map[int]error
+}
+
+// Reply queues data to be returned by the next reads from the device
+// at addr, one element per read. Reads fail if no data is queued.
+func (b *I2C) Reply(addr int, data ...[]byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.replies == nil {
+		b.replies = make(map[int][][]byte)
+	}
+	b.replies[addr] = append(b.replies[addr], data...)
+}
+
+// FailOpen makes opening the device at addr fail with err. If err is
+// nil, ErrInjected is used.
+func (b *I2C) FailOpen(addr int, err error) {
+	if err == nil {
+		err = ErrInjected
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openErrs == nil {
+		b.openErrs = make(map[int]error)
+	}
+	b.openErrs[addr] = err
+}
+
+// Open implements driver.Opener.
+func (b *I2C) Open(addr int, tenbit bool) (driver.Conn, error) {
+	b.mu.Lock()
+	err := b.openErrs[addr]
+	b.mu.Unlock()
+	if err != nil {
+		b.logf("i2c 0x%02x open error: %v", addr, err)
+		return nil, err
+	}
+	b.logf("i2c 0x%02x open", addr)
+	return &i2cConn{bus: b, addr: addr}, nil
+}
+
+func (b *I2C) read(addr int, r []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	q := b.replies[addr]
+	if len(q) == 0 {
+		return errors.New("no scripted reply")
+	}
+	if len(q[0]) != len(r) {
+		return fmt.Errorf("scripted reply is %d bytes long, %d bytes were read", len(q[0]), len(r))
+	}
+	copy(r, q[0])
+	b.replies[addr] = q[1:]
+	return nil
+}
+
+type i2cConn struct {
+	bus    *I2C
+	addr   int
+	closed bool
+}
+
+func (c *i2cConn) Tx(w, r []byte) error {
+	if c.closed {
+		return errors.New("connection is closed")
+	}
+	return c.bus.tx("i2c", fmt.Sprintf("i2c 0x%02x", c.addr), c.addr, w, r, nil, func(r []byte) error {
+		return c.bus.read(c.addr, r)
+	})
+}
+
+func (c *i2cConn) Close() error {
+	c.closed = true
+	c.bus.logf("i2c 0x%02x close", c.addr)
+	return nil
+}
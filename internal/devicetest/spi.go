@@ -0,0 +1,135 @@
+package devicetest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/exp/io/spi/driver"
+)
+
+// SPI is a fake SPI bus. It implements driver.Opener and records the
+// activity of the device opened on it.
+type SPI struct {
+	Recorder
+
+	// MaxTxSize is the maximum size of a transaction, longer ones fail
+	// like they do with the Linux spidev driver. Zero means no limit.
+	MaxTxSize int
+
+	mu      sync.Mutex
+	replies [][]byte
+	config  map[int]int
+	openErr error
+}
+
+// Reply queues data to be returned by the next reads, one element per
+// transaction reading data. Reads fail if no data is queued.
+func (b *SPI) Reply(data ...[]byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.replies = append(b.replies, data...)
+}
+
+// FailOpen makes opening the device fail with err. If err is nil,
+// ErrInjected is used.
+func (b *SPI) FailOpen(err error) {
+	if err == nil {
+		err = ErrInjected
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openErr = err
+}
+
+// Config returns the last value configured for the driver key k, such
+// as driver.MaxSpeed, and whether it was configured at all.
+func (b *SPI) Config(k int) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.config[k]
+	return v, ok
+}
+
+// Open implements driver.Opener.
+func (b *SPI) Open() (driver.Conn, error) {
+	b.mu.Lock()
+	err := b.openErr
+	b.mu.Unlock()
+	if err != nil {
+		b.logf("spi open error: %v", err)
+		return nil, err
+	}
+	b.logf("spi open")
+	return &spiConn{bus: b}, nil
+}
+
+func (b *SPI) configure(k, v int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.config == nil {
+		b.config = make(map[int]int)
+	}
+	b.config[k] = v
+}
+
+func (b *SPI) read(r []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.replies) == 0 {
+		return errors.New("no scripted reply")
+	}
+	if len(b.replies[0]) != len(r) {
+		return fmt.Errorf("scripted reply is %d bytes long, %d bytes were read", len(b.replies[0]), len(r))
+	}
+	copy(r, b.replies[0])
+	b.replies = b.replies[1:]
+	return nil
+}
+
+var configNames = map[int]string{
+	driver.Mode:     "mode",
+	driver.Bits:     "bits",
+	driver.MaxSpeed: "maxspeed",
+	driver.Order:    "order",
+	driver.Delay:    "delay",
+	driver.CSChange: "cschange",
+}
+
+type spiConn struct {
+	bus    *SPI
+	closed bool
+}
+
+func (c *spiConn) Configure(k, v int) error {
+	if c.closed {
+		return errors.New("connection is closed")
+	}
+	name, ok := configNames[k]
+	if !ok {
+		name = fmt.Sprintf("key%d", k)
+	}
+	c.bus.logf("spi configure %s %d", name, v)
+	c.bus.configure(k, v)
+	return nil
+}
+
+func (c *spiConn) Tx(w, r []byte) error {
+	if c.closed {
+		return errors.New("connection is closed")
+	}
+	if w != nil && r != nil && len(w) != len(r) {
+		return errors.New("write and read buffers must have the same length")
+	}
+	var err error
+	if n := c.bus.MaxTxSize; n > 0 && (len(w) > n || len(r) > n) {
+		err = fmt.Errorf("message too long: limit is %d bytes", n)
+	}
+	return c.bus.tx("spi", "spi", 0, w, r, err, c.bus.read)
+}
+
+func (c *spiConn) Close() error {
+	c.closed = true
+	c.bus.logf("spi close")
+	return nil
+}
@@ -0,0 +1,32 @@
+// Package ledstrip holds the helpers shared by the SPI LED strip drivers.
+package ledstrip
+
+import "golang.org/x/exp/io/spi"
+
+// Scale scales the color intensity v by the brightness a, which ranges
+// from 0 (off) to 31 (full brightness) like the A component of
+// dotstar.RGBA. It lets the drivers of strips without a global brightness
+// honor A.
+func Scale(v, a byte) byte {
+	if a >= 31 {
+		return v
+	}
+	return byte(int(v) * int(a) / 31)
+}
+
+// Transmit sends tx on dev in transactions of at most maxTxSize bytes.
+// Zero means no limit. It must only be used for strips which latch their
+// colors on the clock, the pauses between transactions being harmless.
+func Transmit(dev *spi.Device, tx []byte, maxTxSize int) error {
+	for len(tx) > 0 {
+		chunk := tx
+		if maxTxSize > 0 && len(chunk) > maxTxSize {
+			chunk = chunk[:maxTxSize]
+		}
+		if err := dev.Tx(chunk, nil); err != nil {
+			return err
+		}
+		tx = tx[len(chunk):]
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package ledstrip
+
+import "testing"
+
+func TestScale(t *testing.T) {
+	for _, tt := range []struct{ v, a, want byte }{
+		{255, 31, 255},
+		{255, 0, 0},
+		{62, 16, 32},
+		{255, 40, 255},
+	} {
+		if got := Scale(tt.v, tt.a); got != tt.want {
+			t.Errorf("Scale(%d, %d) = %d, want = %d", tt.v, tt.a, got, tt.want)
+		}
+	}
+}
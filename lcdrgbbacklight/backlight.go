@@ -0,0 +1,160 @@
+package lcdrgbbacklight
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// LEDMode is the output mode of the backlight LEDs.
+type LEDMode byte
+
+const (
+	// LEDOff turns the LEDs off.
+	LEDOff LEDMode = 0x0
+	// LEDOn turns the LEDs fully on, ignoring the color.
+	LEDOn LEDMode = 0x1
+	// LEDPWM drives the LEDs with the color set by SetRGB (default).
+	LEDPWM LEDMode = 0x2
+	// LEDPWMGroup drives the LEDs with the color set by SetRGB, modulated
+	// by the group dimming or blinking.
+	LEDPWMGroup LEDMode = 0x3
+)
+
+// fadeStep is the time between two color updates of a fade.
+var fadeStep = 20 * time.Millisecond
+
+// SetLEDMode sets the output mode of the red, green and blue backlight LEDs.
+func (d *LCDRGBBacklight) SetLEDMode(mode LEDMode) error {
+	if mode > LEDPWMGroup {
+		return fmt.Errorf("unknown LED mode %d", mode)
+	}
+	m := byte(mode)
+	return d.setReg(regLEDOut, m|m<<2|m<<4|m<<6)
+}
+
+// Blink makes the backlight blink in hardware. The period must be
+// between 41ms and 10.66s, duty is the fraction of the period the
+// backlight is on. Call StopBlink to stop blinking.
+func (d *LCDRGBBacklight) Blink(period time.Duration, duty float64) error {
+	freq := math.Floor(period.Seconds()*24+0.5) - 1
+	if freq < 0 || freq > 255 {
+		return fmt.Errorf("invalid blink period: %v, should be between 41ms and 10.66s", period)
+	}
+	if duty < 0 || duty > 1 {
+		return fmt.Errorf("invalid blink duty cycle: %v, should be between 0 and 1", duty)
+	}
+	if err := d.setMode2(d.mode2 | mode2DmBlnk); err != nil {
+		return err
+	}
+	if err := d.setReg(regGrpFreq, byte(freq)); err != nil {
+		return err
+	}
+	if err := d.setReg(regGrpPWM, byte(math.Min(255, duty*256))); err != nil {
+		return err
+	}
+	return d.SetLEDMode(LEDPWMGroup)
+}
+
+// StopBlink stops blinking and drives the backlight with its color.
+func (d *LCDRGBBacklight) StopBlink() error {
+	if err := d.SetLEDMode(LEDPWM); err != nil {
+		return err
+	}
+	return d.setMode2(d.mode2 &^ mode2DmBlnk)
+}
+
+// Dim dims the whole backlight in hardware, keeping the color ratios.
+// The level goes from 0 (off) to 255 (no dimming). Dimming stops blinking.
+func (d *LCDRGBBacklight) Dim(level int) error {
+	if level < 0 || level > 255 {
+		return fmt.Errorf("invalid dimming level: %d, should be between 0-255", level)
+	}
+	if err := d.setMode2(d.mode2 &^ mode2DmBlnk); err != nil {
+		return err
+	}
+	if err := d.setReg(regGrpPWM, byte(level)); err != nil {
+		return err
+	}
+	return d.SetLEDMode(LEDPWMGroup)
+}
+
+// SetHSV sets the backlight color from its hue (0-360°), saturation (0-1)
+// and value (0-1).
+func (d *LCDRGBBacklight) SetHSV(h, s, v float64) error {
+	r, g, b := hsvToRGB(h, s, v)
+	return d.SetRGB(r, g, b)
+}
+
+// Fade smoothly changes the backlight color to r, g, b over the duration.
+// Fade blocks until the fade is over or the context is done, in which
+// case the backlight keeps its intermediate color and the context error
+// is returned.
+func (d *LCDRGBBacklight) Fade(ctx context.Context, r, g, b int, duration time.Duration) error {
+	from := d.rgb
+	to := [3]int{r, g, b}
+	steps := int(duration / fadeStep)
+	if steps < 1 {
+		steps = 1
+	}
+	t := time.NewTicker(fadeStep)
+	defer t.Stop()
+	for i := 1; i <= steps; i++ {
+		var c [3]int
+		for j := range c {
+			c[j] = int(from[j]) + (to[j]-int(from[j]))*i/steps
+		}
+		if err := d.SetRGB(c[0], c[1], c[2]); err != nil {
+			return err
+		}
+		if i == steps {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return nil
+}
+
+func (d *LCDRGBBacklight) setMode2(v byte) error {
+	if err := d.setReg(regMode2, v); err != nil {
+		return err
+	}
+	d.mode2 = v
+	return nil
+}
+
+// hsvToRGB converts a color from HSV to 8-bit RGB.
+func hsvToRGB(h, s, v float64) (r, g, b int) {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = math.Max(0, math.Min(1, s))
+	v = math.Max(0, math.Min(1, v))
+
+	c := v * s
+	x := c * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - c
+	var rf, gf, bf float64
+	switch {
+	case h < 60:
+		rf, gf, bf = c, x, 0
+	case h < 120:
+		rf, gf, bf = x, c, 0
+	case h < 180:
+		rf, gf, bf = 0, c, x
+	case h < 240:
+		rf, gf, bf = 0, x, c
+	case h < 300:
+		rf, gf, bf = x, 0, c
+	default:
+		rf, gf, bf = c, 0, x
+	}
+	conv := func(f float64) int { return int(math.Floor((f+m)*255 + 0.5)) }
+	return conv(rf), conv(gf), conv(bf)
+}
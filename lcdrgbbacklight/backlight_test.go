@@ -0,0 +1,114 @@
+package lcdrgbbacklight
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBlink(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.Blink(time.Second, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.StopBlink(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "blink")
+
+	for _, p := range []time.Duration{time.Millisecond, 11 * time.Second} {
+		if err := d.Blink(p, 0.5); err == nil {
+			t.Errorf("Blink(%v) should fail", p)
+		}
+	}
+	if err := d.Blink(time.Second, 1.5); err == nil {
+		t.Error("duty cycles above 1 should be rejected")
+	}
+}
+
+func TestDim(t *testing.T) {
+	d, bus := openDisplay(t)
+	if err := d.Blink(time.Second, 0.5); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := d.Dim(64); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "dim")
+	if err := d.Dim(256); err == nil {
+		t.Error("out of range levels should be rejected")
+	}
+}
+
+func TestSetLEDMode(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	for _, m := range []LEDMode{LEDOff, LEDOn, LEDPWM, LEDPWMGroup} {
+		if err := d.SetLEDMode(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.SetLEDMode(4); err == nil {
+		t.Error("unknown modes should be rejected")
+	}
+	want := "08 00\n08 55\n08 aa\n08 ff"
+	if got := stream(bus.Writes()); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHSVToRGB(t *testing.T) {
+	tests := []struct {
+		h, s, v float64
+		r, g, b int
+	}{
+		{0, 1, 1, 255, 0, 0},
+		{120, 1, 1, 0, 255, 0},
+		{240, 1, 1, 0, 0, 255},
+		{60, 1, 1, 255, 255, 0},
+		{300, 1, 0.5, 128, 0, 128},
+		{30, 0, 1, 255, 255, 255},
+		{-120, 1, 1, 0, 0, 255},
+		{0, 0, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		r, g, b := hsvToRGB(tt.h, tt.s, tt.v)
+		if r != tt.r || g != tt.g || b != tt.b {
+			t.Errorf("hsvToRGB(%v, %v, %v) = %d, %d, %d; want %d, %d, %d", tt.h, tt.s, tt.v, r, g, b, tt.r, tt.g, tt.b)
+		}
+	}
+}
+
+func TestFade(t *testing.T) {
+	defer func(d time.Duration) { fadeStep = d }(fadeStep)
+	fadeStep = time.Millisecond
+
+	d, bus := openDisplay(t)
+	if err := d.SetRGB(0, 100, 200); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := d.Fade(context.Background(), 100, 100, 0, 4*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "fade")
+	if d.rgb != [3]byte{100, 100, 0} {
+		t.Errorf("got color %v after the fade, want [100 100 0]", d.rgb)
+	}
+}
+
+func TestFadeCancel(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := d.Fade(ctx, 0, 0, 0, time.Minute); err != context.Canceled {
+		t.Errorf("got = %v, want = %v", err, context.Canceled)
+	}
+	// Only the first step was sent.
+	if n := len(bus.Writes()); n != 3 {
+		t.Errorf("got %d writes, want 3", n)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/goiot/devices/lcdrgbbacklight"
@@ -16,7 +17,7 @@ func main() {
 	}
 	defer display.Close()
 
-	display.Write("Hello World!")
+	fmt.Fprint(display, "Hello World!")
 	display.SetRGB(0, 255, 0)
 
 	time.Sleep(5 * time.Second)
@@ -26,7 +27,7 @@ func main() {
 	display.SetRGB(255, 124, 0)
 
 	display.SetCustomChar(0, lcdrgbbacklight.CustomLCDChars["smiley"])
-	display.Write("goodbye\nhave a nice day " + string(byte(0)))
+	fmt.Fprintf(display, "goodbye\nhave a nice day %c", 0)
 
 	ticker := time.NewTicker(time.Millisecond * 200)
 	go func() {
@@ -0,0 +1,142 @@
+package lcdrgbbacklight
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goiot/devices/hd44780"
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func openDisplay(t *testing.T) (*LCDRGBBacklight, *devicetest.I2C) {
+	bus := &devicetest.I2C{}
+	d, err := OpenWithTiming(bus, hd44780.Timing{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, bus
+}
+
+// stream formats the writes, one per line.
+func stream(w [][]byte) string {
+	var lines []string
+	for _, b := range w {
+		lines = append(lines, fmt.Sprintf("% x", b))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestOpen(t *testing.T) {
+	bus := &devicetest.I2C{}
+	d, err := Open(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "open")
+}
+
+func TestOpenErrors(t *testing.T) {
+	tests := []struct {
+		failOpen int // address failing to open, if any
+		failTx   int // failing transaction if failOpen is 0
+		step     string
+	}{
+		{failOpen: hd44780.JHD1313Addr, step: "LCD connect"},
+		{failOpen: rgbAddr, step: "RGB connect"},
+		{failTx: 0, step: "function set"},
+		{failTx: 1, step: "display control"},
+		{failTx: 2, step: "clear"},
+		{failTx: 3, step: "entry mode"},
+		{failTx: 4, step: "backlight mode 1"},
+		{failTx: 5, step: "backlight mode 2"},
+		{failTx: 6, step: "backlight LED mode"},
+		{failTx: 7, step: "backlight color"},
+		{failTx: 9, step: "backlight color"},
+	}
+	for _, tt := range tests {
+		bus := &devicetest.I2C{}
+		if tt.failOpen != 0 {
+			bus.FailOpen(tt.failOpen, nil)
+		} else {
+			bus.Fail(tt.failTx, nil)
+		}
+		_, err := OpenWithTiming(bus, hd44780.Timing{})
+		ierr, ok := err.(*InitError)
+		if !ok {
+			t.Errorf("%s: got error %v, want an *InitError", tt.step, err)
+			continue
+		}
+		if ierr.Step != tt.step || ierr.Err != devicetest.ErrInjected || ierr.Attempts != 1 {
+			t.Errorf("got %+v, want step %q failing with the injected error", ierr, tt.step)
+		}
+
+		// Every opened device must be closed.
+		transcript := bus.Transcript()
+		for _, addr := range []string{"0x3e", "0x62"} {
+			opened := strings.Contains(transcript, "i2c "+addr+" open\n")
+			closed := strings.Contains(transcript, "i2c "+addr+" close\n")
+			if opened != closed {
+				t.Errorf("%s: device %s opened: %v, closed: %v", tt.step, addr, opened, closed)
+			}
+		}
+	}
+}
+
+func TestOpenRetry(t *testing.T) {
+	bus := &devicetest.I2C{}
+	// The first instructions to the LCD and to the backlight are NACKed.
+	bus.Fail(0, nil)
+	bus.Fail(5, nil)
+	d, err := OpenWithTiming(bus, hd44780.Timing{Retries: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Close()
+	bus.CheckGolden(t, "open_retry")
+
+	bus = &devicetest.I2C{}
+	// Each attempt fails on its first transaction.
+	bus.Fail(4, nil)
+	bus.Fail(5, nil)
+	bus.Fail(6, nil)
+	_, err = OpenWithTiming(bus, hd44780.Timing{Retries: 2})
+	if ierr, ok := err.(*InitError); !ok || ierr.Step != "backlight mode 1" || ierr.Attempts != 3 {
+		t.Errorf("got %v, want the backlight mode 1 to fail 3 times", err)
+	}
+}
+
+func TestSetRGBAndScroll(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.SetRGB(255, 128, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Scroll(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Scroll(false); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Home(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "rgb_scroll")
+}
+
+func TestScreen(t *testing.T) {
+	d, bus := openDisplay(t)
+	s := NewScreen(d)
+	s.SetLine(0, "Hello")
+	bus.Reset()
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "screen_first_flush")
+	if err := s.SetLine(2, "x"); err != ErrInvalidPosition {
+		t.Errorf("got = %v, want = %v", err, ErrInvalidPosition)
+	}
+}
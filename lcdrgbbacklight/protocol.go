@@ -1,50 +1,46 @@
 package lcdrgbbacklight
 
-import "errors"
+import "github.com/goiot/devices/hd44780"
 
 const (
-	lcdAddr = 0x3E
 	rgbAddr = 0x62
+
+	cols = 16
+	rows = 2
 )
 
+// PCA9633 backlight controller registers.
 const (
-	regRed   = 0x04
-	regGreen = 0x03
-	regBlue  = 0x02
-
-	lcdClearDisplay        = 0x01
-	lcdReturnHome          = 0x02
-	lcdEntryModeSet        = 0x04
-	lcdDisplayControl      = 0x08
-	lcdCursorShift         = 0x10
-	lcdFunctionSet         = 0x20
-	lcdSetCgramAddr        = 0x40
-	lcdSetDdramAddr        = 0x80
-	lcdEntryRight          = 0x00
-	lcdEntryLeft           = 0x02
-	lcdEntryShiftIncrement = 0x01
-	lcdEntryShiftDecrement = 0x00
-	lcdDisplayOn           = 0x04
-	lcdDisplayOff          = 0x00
-	lcdCursorOn            = 0x02
-	lcdCursorOff           = 0x00
-	lcdBlinkOn             = 0x01
-	lcdBlinkOff            = 0x00
-	lcdDisplayMove         = 0x08
-	lcdCursorMove          = 0x00
-	lcdMoveRight           = 0x04
-	lcdMoveLeft            = 0x00
-	lcd2Line               = 0x08
-	lcdCmd                 = 0x80
-	lcdData                = 0x40
-
-	lcd2ndLineOffset = 0x40
+	regMode1   = 0x00
+	regMode2   = 0x01
+	regBlue    = 0x02 // PWM0
+	regGreen   = 0x03 // PWM1
+	regRed     = 0x04 // PWM2
+	regGrpPWM  = 0x06
+	regGrpFreq = 0x07
+	regLEDOut  = 0x08
+
+	mode1AllCall = 0x01 // respond to the LED All Call address, oscillator on
+	mode2DmBlnk  = 0x20 // group control is blinking instead of dimming
 )
 
+// Errors returned by the text methods, see the hd44780 package.
 var (
-	ErrInvalidPosition = errors.New("Invalid position value")
+	ErrInvalidPosition = hd44780.ErrInvalidPosition
+	ErrTooManyGlyphs   = hd44780.ErrTooManyGlyphs
+	ErrUnknownGlyph    = hd44780.ErrUnknownGlyph
 )
 
+// InitError is returned when the initialization of the display fails, see
+// hd44780.InitError. Besides the steps of the LCD controller, Step can be
+// "LCD connect", "RGB connect", "backlight mode 1", "backlight mode 2",
+// "backlight LED mode" or "backlight color".
+type InitError = hd44780.InitError
+
+// CustomLCDChars is a map of CGRAM characters that can be loaded into the
+// display, see hd44780.CustomChars.
+var CustomLCDChars = hd44780.CustomChars
+
 func (d *LCDRGBBacklight) setReg(cmd byte, data byte) error {
 	// TODO(mattetti): reuse a buffer instead of reallocating
 	if err := d.RGB.Write([]byte{cmd, data}); err != nil {
@@ -52,8 +48,3 @@ func (d *LCDRGBBacklight) setReg(cmd byte, data byte) error {
 	}
 	return nil
 }
-
-// TODO(mattetti): change the arg to be variadic
-func (d *LCDRGBBacklight) command(buf []byte) error {
-	return d.LCD.Write(append([]byte{lcdCmd}, buf...))
-}
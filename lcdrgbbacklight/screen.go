@@ -0,0 +1,28 @@
+package lcdrgbbacklight
+
+import "github.com/goiot/devices/hd44780"
+
+// Screen is an in-memory model of the 16x2 display, see hd44780.Screen.
+type Screen = hd44780.Screen
+
+// NewScreen returns a blank screen model for the display d.
+// The first Flush draws every cell.
+func NewScreen(d *LCDRGBBacklight) *Screen {
+	return hd44780.NewScreen(d.Device)
+}
+
+// Marquee scrolls a text longer than 16 characters on a single row of a
+// Screen, see hd44780.Marquee.
+type Marquee = hd44780.Marquee
+
+// MarqueeMode defines how a Marquee scrolls its text.
+type MarqueeMode = hd44780.MarqueeMode
+
+// Marquee modes, see hd44780.Loop and hd44780.Bounce.
+const (
+	Loop   = hd44780.Loop
+	Bounce = hd44780.Bounce
+)
+
+// DefaultMarqueeStep is the scrolling step used when Marquee.Step is zero.
+const DefaultMarqueeStep = hd44780.DefaultMarqueeStep
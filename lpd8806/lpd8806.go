@@ -0,0 +1,116 @@
+// Package lpd8806 implements a driver for the LPD8806 LED strips.
+//
+// The strips share the dotstar strip model: colors are dotstar.RGBA
+// values and LEDs implements dotstar.Strip.
+package lpd8806
+
+import (
+	"github.com/goiot/devices/dotstar"
+	"github.com/goiot/devices/internal/ledstrip"
+	"golang.org/x/exp/io/spi"
+	"golang.org/x/exp/io/spi/driver"
+)
+
+const (
+	// DefaultMaxSpeed is the SPI clock speed (in Hz) set by Open.
+	DefaultMaxSpeed = 2000000
+
+	// DefaultMaxTxSize is the default maximum number of bytes sent in
+	// a single SPI transaction.
+	DefaultMaxTxSize = dotstar.DefaultMaxTxSize
+)
+
+// LEDs represent a strip of LPD8806 LEDs.
+type LEDs struct {
+	// Device is the underlying SPI bus that is used to communicate the
+	// LED strip. Most users don't have to access this field.
+	Device *spi.Device
+
+	// MaxTxSize is the maximum number of bytes sent in a single SPI
+	// transaction. Draw splits longer frames into several transactions.
+	// Zero means no limit.
+	MaxTxSize int
+
+	vals []dotstar.RGBA
+	tx   []byte // frame buffer reused by Draw
+}
+
+// Open opens a new LED strip with n LPD8806 LEDs. The SPI clock is set
+// to DefaultMaxSpeed, use SetMaxSpeed to change it. An LED strip
+// must be closed if no longer in use.
+func Open(o driver.Opener, n int) (*LEDs, error) {
+	dev, err := spi.Open(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dev.SetMode(spi.Mode0); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	if err := dev.SetBitsPerWord(8); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	if err := dev.SetMaxSpeed(DefaultMaxSpeed); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	return &LEDs{
+		Device:    dev,
+		MaxTxSize: DefaultMaxTxSize,
+		vals:      make([]dotstar.RGBA, n),
+	}, nil
+}
+
+// SetMaxSpeed sets the SPI clock speed in Hz. LPD8806 LEDs support
+// clocks up to 20MHz.
+func (d *LEDs) SetMaxSpeed(hz int) error {
+	return d.Device.SetMaxSpeed(hz)
+}
+
+// Len returns the number of LEDs on the strip.
+func (d *LEDs) Len() int {
+	return len(d.vals)
+}
+
+// SetRGBA sets the ith LED's color to the given RGBA value.
+// LPD8806 LEDs have no global brightness, the color is scaled by A
+// which ranges from 0 (off) to 31 (full brightness) like on dotstar
+// LEDs. A call to Draw is required to transmit the new value
+// to the LED strip.
+func (d *LEDs) SetRGBA(i int, v dotstar.RGBA) {
+	d.vals[i] = v
+}
+
+// Draw displays the RGBA values set on the actual LED strip.
+// Each LED gets 7-bit green, red and blue values with the high bit set,
+// the frame ends with one zero byte per 32 LEDs to latch the colors.
+func (d *LEDs) Draw() error {
+	n := len(d.vals)
+	size := 3*n + (n+31)/32
+	if len(d.tx) != size {
+		d.tx = make([]byte, size)
+	}
+	for i, c := range d.vals {
+		j := i * 3
+		d.tx[j] = 0x80 | ledstrip.Scale(c.G, c.A)>>1
+		d.tx[j+1] = 0x80 | ledstrip.Scale(c.R, c.A)>>1
+		d.tx[j+2] = 0x80 | ledstrip.Scale(c.B, c.A)>>1
+	}
+	// latch bytes
+	for i := 3 * n; i < size; i++ {
+		d.tx[i] = 0x00
+	}
+
+	return ledstrip.Transmit(d.Device, d.tx, d.MaxTxSize)
+}
+
+// Close frees the underlying resources. It must be called once
+// the LED strip is no longer in use.
+func (d *LEDs) Close() error {
+	return d.Device.Close()
+}
@@ -0,0 +1,64 @@
+package lpd8806
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goiot/devices/dotstar"
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+var _ dotstar.Strip = (*LEDs)(nil)
+
+func openLEDs(t *testing.T, bus *devicetest.SPI, n int) *LEDs {
+	d, err := Open(bus, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDraw(t *testing.T) {
+	bus := &devicetest.SPI{}
+	d := openLEDs(t, bus, 2)
+	d.SetRGBA(0, dotstar.RGBA{R: 255, G: 128, B: 2, A: 31})
+	d.SetRGBA(1, dotstar.RGBA{R: 62, G: 31, B: 255, A: 0})
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "draw")
+
+	want := []byte{
+		0xc0, 0xff, 0x81, // GRB with the high bit set
+		0x80, 0x80, 0x80,
+		0x00, // latch
+	}
+	if got := bus.Writes()[0]; !bytes.Equal(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestLatchLength(t *testing.T) {
+	for _, tt := range []struct{ n, latch int }{
+		{1, 1}, {32, 1}, {33, 2}, {64, 2}, {100, 4},
+	} {
+		bus := &devicetest.SPI{}
+		d := openLEDs(t, bus, tt.n)
+		if err := d.Draw(); err != nil {
+			t.Fatal(err)
+		}
+		w := bus.Written()
+		if got := len(w) - 3*tt.n; got != tt.latch {
+			t.Errorf("%d LEDs: got %d latch bytes, want = %d", tt.n, got, tt.latch)
+		}
+		for _, b := range w[3*tt.n:] {
+			if b != 0 {
+				t.Errorf("%d LEDs: latch bytes must be zeros, got %v", tt.n, w[3*tt.n:])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"time"
+
+	"github.com/goiot/devices/monochromeoled"
+	"golang.org/x/exp/io/i2c"
+)
+
+func main() {
+	d, err := monochromeoled.Open(&i2c.Devfs{Dev: "/dev/i2c-1"})
+	if err != nil {
+		panic(err)
+	}
+	defer d.Close()
+
+	r := monochromeoled.NewRenderer(d, 30)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+
+	// bounce a ball, the renderer only sends the parts which changed
+	x, y, dx, dy := 10, 10, 2, 1
+	t := time.NewTicker(time.Second / 60)
+	defer t.Stop()
+	for ctx.Err() == nil {
+		<-t.C
+		r.Frame(func(b *monochromeoled.OLED) {
+			b.FillRect(image.Rect(x-4, y-4, x+5, y+5), monochromeoled.ModeClear)
+			x, y = x+dx, y+dy
+			if x < 4 || x > b.Width()-5 {
+				dx = -dx
+			}
+			if y < 4 || y > b.Height()-5 {
+				dy = -dy
+			}
+			b.Circle(x, y, 4, monochromeoled.ModeSet)
+		})
+	}
+	<-done
+
+	s := r.Stats()
+	fmt.Printf("%d frames, %.1f fps, %.0f B/s, %v per frame\n",
+		s.Frames, s.FPS(), s.Throughput(), s.AvgFrameTime())
+}
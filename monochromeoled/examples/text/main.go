@@ -0,0 +1,28 @@
+package main
+
+import (
+	"image"
+
+	"github.com/goiot/devices/monochromeoled"
+	"golang.org/x/exp/io/i2c"
+)
+
+func main() {
+	d, err := monochromeoled.Open(&i2c.Devfs{Dev: "/dev/i2c-1"})
+	if err != nil {
+		panic(err)
+	}
+	defer d.Close()
+
+	d.DrawText(d.Width()/2, 0, "Hello!", monochromeoled.TextStyle{
+		Face:    monochromeoled.Font8x16,
+		Align:   monochromeoled.AlignCenter,
+		Inverse: true,
+	})
+	d.DrawTextBox(image.Rect(0, 20, d.Width(), d.Height()),
+		"The quick brown fox jumps over the lazy dog.",
+		monochromeoled.TextStyle{Align: monochromeoled.AlignCenter})
+	if err := d.Draw(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,160 @@
+package monochromeoled
+
+import (
+	"image"
+	"image/color"
+)
+
+// Bit is the color of a pixel of the display, either on or off.
+type Bit bool
+
+// RGBA implements color.Color, pixels that are on are white.
+func (b Bit) RGBA() (r, g, bl, a uint32) {
+	if b {
+		return 0xffff, 0xffff, 0xffff, 0xffff
+	}
+	return 0, 0, 0, 0xffff
+}
+
+// BitModel is the color model of the display. Colors at least half as
+// bright as white are on.
+var BitModel color.Model = color.ModelFunc(bitModel)
+
+func bitModel(c color.Color) color.Color {
+	if b, ok := c.(Bit); ok {
+		return b
+	}
+	return Bit(luminance(c) >= 0x80)
+}
+
+// luminance returns the luminance of c between 0 and 255, transparent
+// colors being black.
+func luminance(c color.Color) int {
+	return int(color.GrayModel.Convert(c).(color.Gray).Y)
+}
+
+// Dithering is an algorithm converting images to pixels that are either
+// on or off.
+type Dithering int
+
+const (
+	// DitherThreshold turns on the pixels whose luminance is at least
+	// the threshold of the display. It suits text and line art.
+	DitherThreshold Dithering = iota
+
+	// DitherFloydSteinberg diffuses the error of each pixel to its
+	// neighbours. It suits photos.
+	DitherFloydSteinberg
+
+	// DitherBayer compares the pixels to a 4x4 ordered threshold matrix.
+	// It suits gradients and animations as it produces stable patterns.
+	// The threshold of the display is ignored.
+	DitherBayer
+)
+
+// bayer4 is the 4x4 Bayer threshold matrix.
+var bayer4 = [4][4]int{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+// ColorModel implements draw.Image, it returns BitModel.
+func (o *OLED) ColorModel() color.Model { return BitModel }
+
+// Bounds implements draw.Image.
+func (o *OLED) Bounds() image.Rectangle { return image.Rect(0, 0, o.w, o.h) }
+
+// At implements draw.Image, it returns the Bit of the pixel at x, y.
+func (o *OLED) At(x, y int) color.Color {
+	if !(image.Point{x, y}.In(o.Bounds())) {
+		return Bit(false)
+	}
+	return Bit(o.buf[x+(y/8)*o.w]&(1<<uint(y&7)) != 0)
+}
+
+// Set implements draw.Image, it turns the pixel at x, y on if the
+// luminance of c is at least the threshold of the display. Pixels out of
+// bounds are ignored. A call to Draw is required to display it on the
+// OLED display.
+func (o *OLED) Set(x, y int, c color.Color) {
+	if !(image.Point{x, y}.In(o.Bounds())) {
+		return
+	}
+	if b, ok := c.(Bit); ok {
+		o.set(x, y, bool(b))
+		return
+	}
+	o.set(x, y, luminance(c) >= o.threshold())
+}
+
+func (o *OLED) threshold() int {
+	if o.Threshold == 0 {
+		return 0x80
+	}
+	return int(o.Threshold)
+}
+
+// SetImage draws an image on the display buffer, the top left corner of
+// its bounds being at x, y, and converts it with the display's Dithering.
+// The parts out of the display are clipped. A call to Draw is required to
+// display it on the OLED display.
+func (o *OLED) SetImage(x, y int, img image.Image) error {
+	b := img.Bounds()
+	r := b.Sub(b.Min).Add(image.Pt(x, y)).Intersect(o.Bounds())
+	if r.Empty() {
+		return nil
+	}
+	// offset from the display to the image coordinates
+	d := b.Min.Sub(image.Pt(x, y))
+	switch o.Dithering {
+	case DitherFloydSteinberg:
+		o.floydSteinberg(r, d, img)
+	case DitherBayer:
+		for py := r.Min.Y; py < r.Max.Y; py++ {
+			for px := r.Min.X; px < r.Max.X; px++ {
+				l := luminance(img.At(px+d.X, py+d.Y))
+				o.set(px, py, l > bayer4[py&3][px&3]*16+8)
+			}
+		}
+	default:
+		level := o.threshold()
+		for py := r.Min.Y; py < r.Max.Y; py++ {
+			for px := r.Min.X; px < r.Max.X; px++ {
+				o.set(px, py, luminance(img.At(px+d.X, py+d.Y)) >= level)
+			}
+		}
+	}
+	return nil
+}
+
+// floydSteinberg draws the r area of the display from img, d being the
+// offset from the display to the image coordinates.
+func (o *OLED) floydSteinberg(r image.Rectangle, d image.Point, img image.Image) {
+	level := o.threshold()
+	w := r.Dx()
+	// errors diffused to the current and next rows, with a margin on
+	// each side
+	cur, next := make([]int, w+2), make([]int, w+2)
+	for py := r.Min.Y; py < r.Max.Y; py++ {
+		for i := range next {
+			next[i] = 0
+		}
+		for i := 0; i < w; i++ {
+			px := r.Min.X + i
+			v := luminance(img.At(px+d.X, py+d.Y)) + cur[i+1]/16
+			on := v >= level
+			o.set(px, py, on)
+			e := v
+			if on {
+				e -= 255
+			}
+			cur[i+2] += e * 7
+			next[i] += e * 3
+			next[i+1] += e * 5
+			next[i+2] += e
+		}
+		cur, next = next, cur
+	}
+}
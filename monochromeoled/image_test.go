@@ -0,0 +1,110 @@
+package monochromeoled
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+var _ draw.Image = (*OLED)(nil)
+
+// countOn returns the number of pixels on in r.
+func countOn(o *OLED, r image.Rectangle) int {
+	n := 0
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if o.At(x, y) == Bit(true) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestDrawImage(t *testing.T) {
+	o, _ := openOLED(t)
+	draw.Draw(o, image.Rect(10, 10, 20, 12), image.White, image.Point{}, draw.Src)
+	if n := countOn(o, o.Bounds()); n != 20 {
+		t.Errorf("got %d pixels on, want 20", n)
+	}
+	if o.At(10, 10) != Bit(true) || o.At(9, 10) != Bit(false) || o.At(200, 10) != Bit(false) {
+		t.Error("At returned the wrong pixels")
+	}
+	// Pixels out of bounds are ignored.
+	o.Set(-1, 0, color.White)
+	o.Set(128, 0, color.White)
+
+	if got := BitModel.Convert(color.Gray{Y: 0x7f}); got != Bit(false) {
+		t.Errorf("dark gray converted to %v", got)
+	}
+	if got := BitModel.Convert(color.Gray{Y: 0x80}); got != Bit(true) {
+		t.Errorf("light gray converted to %v", got)
+	}
+}
+
+func TestSetImageSubImage(t *testing.T) {
+	o, _ := openOLED(t)
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	img.SetGray(8, 8, color.Gray{Y: 255})
+	// The sub-image starts at 8, 8 which is drawn at 2, 3.
+	if err := o.SetImage(2, 3, img.SubImage(image.Rect(8, 8, 16, 16))); err != nil {
+		t.Fatal(err)
+	}
+	if o.At(2, 3) != Bit(true) || countOn(o, o.Bounds()) != 1 {
+		t.Error("the sub-image was not drawn at its origin")
+	}
+	// Images are clipped.
+	if err := o.SetImage(-8, 60, img); err != nil {
+		t.Fatal(err)
+	}
+	if o.At(0, 63) != Bit(false) {
+		t.Error("the clipped image should be blank at 0, 63")
+	}
+}
+
+func TestSetImageThreshold(t *testing.T) {
+	o, _ := openOLED(t)
+	img := image.NewUniform(color.Gray{Y: 0x10})
+	sub := image.Rect(0, 0, 8, 8)
+	if err := o.SetImage(0, 0, &subImage{img, sub}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countOn(o, sub); n != 0 {
+		t.Errorf("got %d pixels on with the default threshold, want 0", n)
+	}
+	o.Threshold = 1
+	if err := o.SetImage(0, 0, &subImage{img, sub}); err != nil {
+		t.Fatal(err)
+	}
+	if n := countOn(o, sub); n != 64 {
+		t.Errorf("got %d pixels on with a threshold of 1, want 64", n)
+	}
+}
+
+func TestSetImageDithering(t *testing.T) {
+	gray := &subImage{image.NewUniform(color.Gray{Y: 0x80}), image.Rect(0, 0, 32, 32)}
+	for _, d := range []Dithering{DitherFloydSteinberg, DitherBayer} {
+		o, _ := openOLED(t)
+		o.Dithering = d
+		if err := o.SetImage(0, 0, gray); err != nil {
+			t.Fatal(err)
+		}
+		// Half of the pixels are on, spread evenly.
+		for y := 0; y < 32; y += 8 {
+			for x := 0; x < 32; x += 8 {
+				if n := countOn(o, image.Rect(x, y, x+8, y+8)); n < 28 || n > 36 {
+					t.Errorf("dithering %d: got %d pixels on in the block at %d, %d, want about 32", d, n, x, y)
+				}
+			}
+		}
+	}
+}
+
+// subImage bounds an image, such as an image.Uniform.
+type subImage struct {
+	image.Image
+	r image.Rectangle
+}
+
+func (s *subImage) Bounds() image.Rectangle { return s.r }
@@ -1,12 +1,15 @@
 // Package monochromeoled contains an Adafruit Monochrome OLED (SSD1306)
-// display driver.
+// display driver. The SH1106 controller of the common 1.3" displays is
+// supported too, see Options.
+//
+// The controller is driven through a Transport, this package provides the
+// transports of the I2C and 4-wire SPI modules.
 package monochromeoled
 
 import (
 	"fmt"
 	"image"
 
-	"golang.org/x/exp/io/i2c"
 	"golang.org/x/exp/io/i2c/driver"
 )
 
@@ -14,12 +17,26 @@ const (
 	ssd1306_LCDWIDTH  = 128
 	ssd1306_LCDHEIGHT = 64
 
-	addr = 0x3C // addr is the I2C address of the device.
+	// Addr is the default I2C address of the device.
+	Addr = 0x3C
+	// AltAddr is the I2C address of the device when its SA0 pin is high.
+	AltAddr = 0x3D
 
 	// On or off registers.
 	ssd1306_DISPLAY_ON  = 0xAF
 	ssd1306_DISPLAY_OFF = 0xAE
 
+	// Display settings registers.
+	ssd1306_SET_CONTRAST         = 0x81
+	ssd1306_NORMAL_DISPLAY       = 0xA6
+	ssd1306_INVERT_DISPLAY       = 0xA7
+	ssd1306_SEG_REMAP            = 0xA0
+	ssd1306_COM_SCAN_INC         = 0xC0
+	ssd1306_COM_SCAN_DEC         = 0xC8
+	ssd1306_CHARGE_PUMP          = 0x8D
+	ssd1306_CHARGE_PUMP_ENABLED  = 0x14
+	ssd1306_CHARGE_PUMP_DISABLED = 0x10
+
 	// Scrolling registers.
 	ssd1306_ACTIVATE_SCROLL                      = 0x2F
 	ssd1306_DEACTIVATE_SCROLL                    = 0x2E
@@ -32,146 +49,366 @@ const (
 
 // OLED represents an SSD1306 OLED display.
 type OLED struct {
-	dev *i2c.Device
-
-	w   int    // width of the display
-	h   int    // height of the display
-	buf []byte // each pixel is represented by a bit
-}
-
-var initSeq = []byte{
-	0xae,
-	0x00 | 0x00, // row offset
-	0x10 | 0x00, // column offset
-	0xd5, 0x40,
-	0xa8, ssd1306_LCDHEIGHT - 1,
-	0xd3, 0x00, // set display offset to no offset
-	0x40 | 0,
-	0x8d, 0x14,
-	0x20, 0x0,
-
-	0xA0 | 0x1,
-	0xC8,
-	0xda, 0x12,
-	0x81, 0xcf, // set contrast
-	0x9d, 0xf1,
-	0xdb, 0x40,
-	0xa4, 0xa6,
-
-	0x2e,
-	0xaf,
-}
-
-// Open opens an SSD1306 OLED display. Once not in use, it needs to
-// be close by calling Close.
-// The default width is 128, height is 64 if zero values are given.
+	// Dithering is the algorithm used by SetImage to convert images
+	// to pixels that are either on or off.
+	Dithering Dithering
+
+	// Threshold is the luminance at or above which pixels are on when
+	// converting colors, see Dithering. Zero value is 128, use 1 to turn
+	// on every pixel that is not black.
+	Threshold uint8
+
+	t          Transport
+	controller Controller
+
+	w         int    // width of the display
+	h         int    // height of the display
+	colOffset int    // first RAM column shown
+	buf       []byte // each pixel is represented by a bit
+	tx        []byte // data sent by Draw when only a part of buf is sent
+
+	// dirty is the part of buf changed since the last Draw, X being the
+	// columns and Y the pages.
+	dirty image.Rectangle
+
+	scrolling bool // whether the hardware scrolling is active
+
+	flipH, flipV bool // whether the display is flipped
+}
+
+// panel is the configuration of a supported display size.
+type panel struct {
+	comPins   byte // COM pins hardware configuration
+	colOffset int  // first RAM column shown
+}
+
+var panels = map[image.Point]panel{
+	{128, 64}: {comPins: 0x12},
+	{128, 32}: {comPins: 0x02},
+	{96, 16}:  {comPins: 0x02},
+	{64, 48}:  {comPins: 0x12, colOffset: 32},
+}
+
+// initSeq returns the initialization sequence of a display h pixels high.
+func initSeq(h int, p panel) []byte {
+	return []byte{
+		0xae,
+		0x00 | 0x00, // row offset
+		0x10 | 0x00, // column offset
+		0xd5, 0x40,
+		0xa8, byte(h - 1), // multiplex ratio
+		0xd3, 0x00, // set display offset to no offset
+		0x40 | 0,
+		0x8d, 0x14,
+		0x20, 0x0,
+
+		0xA0 | 0x1,
+		0xC8,
+		0xda, p.comPins,
+		0x81, 0xcf, // set contrast
+		0x9d, 0xf1,
+		0xdb, 0x40,
+		0xa4, 0xa6,
+
+		0x2e,
+		0xaf,
+	}
+}
+
+// Controller is the controller of a display.
+type Controller int
+
+const (
+	SSD1306 Controller = iota
+	SH1106
+)
+
+// Options are the display options, see New and OpenWithOptions.
+type Options struct {
+	// Controller is the controller of the display. Zero value is SSD1306.
+	Controller Controller
+
+	// Width and Height are the size of the display in pixels. The
+	// supported sizes are 128x64, 128x32, 96x16 and 64x48 with an SSD1306
+	// and 128x64 with an SH1106. Zero values are 128 and 64.
+	Width, Height int
+
+	// Addr is the I2C address of the display, Addr or AltAddr. It is only
+	// used by OpenWithOptions. Zero value is Addr.
+	Addr int
+
+	// Reset is the line connected to the reset input of the display, if
+	// any. The display is reset before being initialized.
+	Reset Pin
+}
+
+// Open opens a 128x64 SSD1306 OLED display at the default address. Once
+// not in use, it needs to be close by calling Close.
 func Open(o driver.Opener) (*OLED, error) {
-	w := ssd1306_LCDWIDTH
-	h := ssd1306_LCDHEIGHT
-	dev, err := i2c.Open(o, addr)
+	return OpenWithOptions(o, Options{})
+}
+
+// OpenWithOptions opens an SSD1306 OLED display of the given size and
+// address on an I2C bus. Once not in use, it needs to be close by calling
+// Close.
+func OpenWithOptions(o driver.Opener, opts Options) (*OLED, error) {
+	a := opts.Addr
+	if a == 0 {
+		a = Addr
+	}
+	if a != Addr && a != AltAddr {
+		return nil, fmt.Errorf("invalid I2C address %#x, want %#x or %#x", a, Addr, AltAddr)
+	}
+	if _, _, _, err := geometry(opts); err != nil {
+		return nil, err
+	}
+	t, err := OpenI2C(o, a)
 	if err != nil {
 		return nil, err
 	}
-	if err := dev.Write(initSeq); err != nil {
+	display, err := New(t, opts)
+	if err != nil {
+		t.Close()
 		return nil, err
 	}
-	buf := make([]byte, w*(h/8)+1)
-	buf[0] = 0x40 // start frame of pixel data
-	return &OLED{dev: dev, w: w, h: h, buf: buf}, nil
+	return display, nil
+}
+
+// New initializes the SSD1306 controller behind the transport, such as an
+// I2C or SPI transport. The transport is left open on errors.
+func New(t Transport, opts Options) (*OLED, error) {
+	w, h, p, err := geometry(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Reset != nil {
+		if err := reset(opts.Reset); err != nil {
+			return nil, err
+		}
+	}
+	seq := initSeq(h, p)
+	if opts.Controller == SH1106 {
+		seq = sh1106InitSeq(h, p)
+	}
+	if err := t.Command(seq...); err != nil {
+		return nil, err
+	}
+	o := &OLED{
+		t:          t,
+		controller: opts.Controller,
+		w:          w,
+		h:          h,
+		colOffset:  p.colOffset,
+		buf:        make([]byte, w*(h/8)),
+	}
+	// the display RAM content is unknown until the first Draw
+	o.dirty = o.pages()
+	return o, nil
+}
+
+// geometry returns the size of the display configured by opts.
+func geometry(opts Options) (w, h int, p panel, err error) {
+	w, h = opts.Width, opts.Height
+	if w == 0 {
+		w = ssd1306_LCDWIDTH
+	}
+	if h == 0 {
+		h = ssd1306_LCDHEIGHT
+	}
+	supported := panels
+	switch opts.Controller {
+	case SSD1306:
+	case SH1106:
+		supported = sh1106Panels
+	default:
+		return 0, 0, p, fmt.Errorf("unknown controller %d", opts.Controller)
+	}
+	p, ok := supported[image.Pt(w, h)]
+	if !ok {
+		return 0, 0, p, fmt.Errorf("unsupported display size %dx%d", w, h)
+	}
+	return w, h, p, nil
 }
 
 // On turns on the display if it is off.
 func (o *OLED) On() error {
-	return o.dev.Write([]byte{ssd1306_DISPLAY_ON})
+	return o.t.Command(ssd1306_DISPLAY_ON)
 }
 
 // Off turns off the display if it is on.
 func (o *OLED) Off() error {
-	return o.dev.Write([]byte{ssd1306_DISPLAY_OFF})
+	return o.t.Command(ssd1306_DISPLAY_OFF)
+}
+
+// SetContrast sets the contrast of the display, from 0 to 255. The
+// contrast set by Open is 0xcf.
+func (o *OLED) SetContrast(c byte) error {
+	return o.t.Command(ssd1306_SET_CONTRAST, c)
+}
+
+// Invert inverts the display if on is true, pixels that are on are then
+// shown as off and vice versa. The buffer is not changed.
+func (o *OLED) Invert(on bool) error {
+	if on {
+		return o.t.Command(ssd1306_INVERT_DISPLAY)
+	}
+	return o.t.Command(ssd1306_NORMAL_DISPLAY)
+}
+
+// SetFlip mirrors the display horizontally and vertically. Flipping
+// horizontally only applies to the data written afterwards, so the whole
+// buffer is drawn again in this case.
+func (o *OLED) SetFlip(horizontal, vertical bool) error {
+	if vertical != o.flipV {
+		cmd := byte(ssd1306_COM_SCAN_DEC)
+		if vertical {
+			cmd = ssd1306_COM_SCAN_INC
+		}
+		if err := o.t.Command(cmd); err != nil {
+			return err
+		}
+		o.flipV = vertical
+	}
+	if horizontal != o.flipH {
+		cmd := byte(ssd1306_SEG_REMAP | 0x1)
+		if horizontal {
+			cmd = ssd1306_SEG_REMAP
+		}
+		if err := o.t.Command(cmd); err != nil {
+			return err
+		}
+		o.flipH = horizontal
+		return o.Refresh()
+	}
+	return nil
+}
+
+// Rotate rotates the display by 180 degrees if rotated is true, see
+// SetFlip.
+func (o *OLED) Rotate(rotated bool) error {
+	return o.SetFlip(rotated, rotated)
+}
+
+// Sleep turns off the display and its charge pump, or DC-DC converter on
+// the SH1106, the controller then
+// draws a few µA. The display RAM is retained, see Wake.
+func (o *OLED) Sleep() error {
+	if o.controller == SH1106 {
+		return o.t.Command(ssd1306_DISPLAY_OFF, sh1106_DCDC, sh1106_DCDC_OFF)
+	}
+	return o.t.Command(
+		ssd1306_DISPLAY_OFF,
+		ssd1306_CHARGE_PUMP, ssd1306_CHARGE_PUMP_DISABLED,
+	)
+}
+
+// Wake turns on the charge pump and the display after Sleep.
+func (o *OLED) Wake() error {
+	if o.controller == SH1106 {
+		return o.t.Command(sh1106_DCDC, sh1106_DCDC_ON, ssd1306_DISPLAY_ON)
+	}
+	return o.t.Command(
+		ssd1306_CHARGE_PUMP, ssd1306_CHARGE_PUMP_ENABLED,
+		ssd1306_DISPLAY_ON,
+	)
 }
 
 // Clear clears the entire display.
 func (o *OLED) Clear() error {
-	for i := 1; i < len(o.buf); i++ {
+	for i := range o.buf {
 		o.buf[i] = 0
 	}
+	o.dirty = o.pages()
 	return o.Draw()
 }
 
+// SetPixel turns the pixel at x, y on if v is 1 or off if v is 0.
+// A call to Draw is required to display it on the OLED display.
 func (o *OLED) SetPixel(x, y int, v byte) error {
-	if x >= o.w || y >= o.h {
+	if x < 0 || y < 0 || x >= o.w || y >= o.h {
 		return fmt.Errorf("(x=%v, y=%v) is out of bounds on this %vx%v display", x, y, o.w, o.h)
 	}
 	if v > 1 {
 		return fmt.Errorf("value needs to be either 0 or 1; given %v", v)
 	}
-	i := 1 + x + (y/8)*o.w
-	if v == 0 {
-		o.buf[i] &= ^(1 << uint((y & 7)))
-	} else {
-		o.buf[i] |= 1 << uint((y & 7))
-	}
+	o.set(x, y, v == 1)
 	return nil
 }
 
-// SetImage draws an image on the display buffer starting from x, y.
-// A call to Draw is required to display it on the OLED display.
-func (o *OLED) SetImage(x, y int, img image.Image) error {
-	imgW := img.Bounds().Dx()
-	imgH := img.Bounds().Dy()
-
-	endX := x + imgW
-	endY := y + imgH
-
-	if endX >= o.w {
-		endX = o.w
-	}
-	if endY >= o.h {
-		endY = o.h
-	}
-
-	var imgI, imgY int
-	for i := x; i < endX; i++ {
-		imgY = 0
-		for j := y; j < endY; j++ {
-			r, g, b, _ := img.At(imgI, imgY).RGBA()
-			var v byte
-			if r+g+b > 0 {
-				v = 0x1
-			}
-			if err := o.SetPixel(i, j, v); err != nil {
-				return err
-			}
-			imgY++
-		}
-		imgI++
+// set turns the pixel at x, y on or off, x and y must be within bounds.
+func (o *OLED) set(x, y int, on bool) {
+	i := x + (y/8)*o.w
+	v := o.buf[i]
+	if on {
+		v |= 1 << uint((y & 7))
+	} else {
+		v &= ^(1 << uint((y & 7)))
 	}
-	return nil
+	if v != o.buf[i] {
+		o.buf[i] = v
+		o.dirty = o.dirty.Union(image.Rect(x, y/8, x+1, y/8+1))
+	}
+}
+
+// pages returns the whole display in columns and pages.
+func (o *OLED) pages() image.Rectangle {
+	return image.Rect(0, 0, o.w, o.h/8)
 }
 
 // Draw draws the intermediate pixel buffer on the display.
-// See SetPixel and SetImage to mutate the buffer.
+// See SetPixel and SetImage to mutate the buffer. Only the columns and
+// pages changed since the last Draw are sent, see Refresh.
+// Scrolling is deactivated first as the RAM content may be corrupted
+// otherwise, call EnableScroll again to resume it.
 func (o *OLED) Draw() error {
-	if err := o.dev.Write([]byte{
-		0xa4,     // write mode
-		0x40 | 0, // start line = 0
-		0x21, 0, ssd1306_LCDWIDTH,
-		0x22, 0, 7,
-	}); err != nil { // the write mode
+	if o.scrolling {
+		// the RAM content is scrolled along with the display
+		o.dirty = o.pages()
+	}
+	if err := o.DisableScroll(); err != nil {
+		return err
+	}
+	if o.dirty.Empty() {
+		return nil
+	}
+	draw := o.drawWindow
+	if o.controller == SH1106 {
+		draw = o.drawPages
+	}
+	if err := draw(o.dirty); err != nil {
 		return err
 	}
-	return o.dev.Write(o.buf)
+	o.dirty = image.Rectangle{}
+	return nil
 }
 
-// StartScroll starts scrolling in the horizontal direction starting from
-// startY column to endY column.
-func (o *OLED) EnableScroll(startY, endY int) error {
-	panic("not implemented")
+// drawWindow sends the r area of the buffer, r being in columns and
+// pages, in a single write using the horizontal addressing mode.
+func (o *OLED) drawWindow(r image.Rectangle) error {
+	if err := o.t.Command(
+		0xa4,   // write mode
+		0x40|0, // start line = 0
+		0x21, byte(o.colOffset+r.Min.X), byte(o.colOffset+r.Max.X-1),
+		0x22, byte(r.Min.Y), byte(r.Max.Y-1),
+	); err != nil { // the write mode
+		return err
+	}
+	data := o.buf[r.Min.Y*o.w : r.Max.Y*o.w]
+	if r.Dx() != o.w {
+		o.tx = o.tx[:0]
+		for page := r.Min.Y; page < r.Max.Y; page++ {
+			o.tx = append(o.tx, o.buf[page*o.w+r.Min.X:page*o.w+r.Max.X]...)
+		}
+		data = o.tx
+	}
+	return o.t.Data(data)
 }
 
-// StopStrolls stops the scrolling on the display.
-func (o *OLED) DisableScroll() error {
-	panic("not implemented")
+// Refresh draws the whole intermediate pixel buffer on the display, even
+// the parts which didn't change since the last Draw.
+func (o *OLED) Refresh() error {
+	o.dirty = o.pages()
+	return o.Draw()
 }
 
 // Width returns the display width.
@@ -180,7 +417,7 @@ func (o *OLED) Width() int { return o.w }
 // Height returns the display height.
 func (o *OLED) Height() int { return o.h }
 
-// Close closes the display.
+// Close closes the transport of the display.
 func (o *OLED) Close() error {
-	return o.dev.Close()
+	return o.t.Close()
 }
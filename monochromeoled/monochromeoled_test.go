@@ -0,0 +1,242 @@
+package monochromeoled
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func openOLED(t *testing.T) (*OLED, *devicetest.I2C) {
+	bus := &devicetest.I2C{}
+	o, err := Open(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o, bus
+}
+
+func TestOpen(t *testing.T) {
+	o, bus := openOLED(t)
+	if o.Width() != 128 || o.Height() != 64 {
+		t.Errorf("got a %dx%d display, want 128x64", o.Width(), o.Height())
+	}
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "open")
+}
+
+func TestOpenWithOptions(t *testing.T) {
+	for _, opts := range []Options{
+		{Width: 128, Height: 32},
+		{Width: 96, Height: 16, Addr: AltAddr},
+		{Width: 64, Height: 48},
+	} {
+		bus := &devicetest.I2C{}
+		o, err := OpenWithOptions(bus, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o.Width() != opts.Width || o.Height() != opts.Height {
+			t.Errorf("got a %dx%d display, want %dx%d", o.Width(), o.Height(), opts.Width, opts.Height)
+		}
+		if err := o.SetPixel(opts.Width-1, opts.Height-1, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := o.Draw(); err != nil {
+			t.Fatal(err)
+		}
+		bus.CheckGolden(t, fmt.Sprintf("open_%dx%d", opts.Width, opts.Height))
+	}
+}
+
+func TestOpenWithOptionsErrors(t *testing.T) {
+	for _, opts := range []Options{
+		{Width: 128, Height: 48},
+		{Width: 64},
+		{Addr: 0x3e},
+	} {
+		bus := &devicetest.I2C{}
+		if _, err := OpenWithOptions(bus, opts); err == nil {
+			t.Errorf("OpenWithOptions(%+v) should fail", opts)
+		}
+		if n := len(bus.Txs()); n != 0 {
+			t.Errorf("%+v: nothing should be written on error, got %d transactions", opts, n)
+		}
+	}
+}
+
+func TestSetPixel(t *testing.T) {
+	o, _ := openOLED(t)
+	if err := o.SetPixel(3, 10, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.buf[3+128]; got != 1<<2 {
+		t.Errorf("got %08b, want %08b", got, 1<<2)
+	}
+	if err := o.SetPixel(3, 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.buf[3+128]; got != 0 {
+		t.Errorf("got %08b, want 0", got)
+	}
+	if err := o.SetPixel(128, 0, 1); err == nil {
+		t.Error("out of bounds pixels should be rejected")
+	}
+	if err := o.SetPixel(0, 0, 2); err == nil {
+		t.Error("invalid pixel values should be rejected")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	o, bus := openOLED(t)
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := 0; i < 8; i++ {
+		img.SetGray(i, i, color.Gray{Y: 255})
+	}
+	if err := o.SetImage(0, 0, img); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "draw")
+}
+
+func TestClear(t *testing.T) {
+	o, bus := openOLED(t)
+	o.SetPixel(0, 0, 1)
+	bus.Reset()
+	if err := o.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "clear")
+}
+
+func TestDrawDirty(t *testing.T) {
+	o, bus := openOLED(t)
+	// The first Draw sends the whole buffer.
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+
+	// A single pixel.
+	o.SetPixel(3, 10, 1)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	// Nothing changed.
+	o.SetPixel(3, 10, 1)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	// The window covers both pixels.
+	o.SetPixel(100, 0, 1)
+	o.SetPixel(5, 60, 1)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	// A full row of pages.
+	draw.Draw(o, image.Rect(0, 16, 128, 24), image.White, image.Point{}, draw.Src)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "dirty")
+}
+
+func TestDrawDirtyOffset(t *testing.T) {
+	bus := &devicetest.I2C{}
+	o, err := OpenWithOptions(bus, Options{Width: 64, Height: 48})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	// The panel is centered in the 128 columns of the controller.
+	w := bus.Writes()
+	if want := []byte{0x00, 0xa4, 0x40, 0x21, 32, 95, 0x22, 0, 5}; string(w[0]) != string(want) {
+		t.Errorf("got window % x, want % x", w[0], want)
+	}
+	bus.Reset()
+	o.SetPixel(0, 47, 1)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	w = bus.Writes()
+	if want := []byte{0x00, 0xa4, 0x40, 0x21, 32, 32, 0x22, 5, 5}; string(w[0]) != string(want) {
+		t.Errorf("got window % x, want % x", w[0], want)
+	}
+	if want := []byte{0x40, 0x80}; string(w[1]) != string(want) {
+		t.Errorf("got data % x, want % x", w[1], want)
+	}
+}
+
+func TestDrawError(t *testing.T) {
+	o, bus := openOLED(t)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	o.SetPixel(0, 0, 1)
+	bus.Reset()
+	bus.Fail(1, nil)
+	if err := o.Draw(); err == nil {
+		t.Fatal("expected an error")
+	}
+	// The failed window is sent again.
+	bus.Reset()
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(bus.Txs()); n != 2 {
+		t.Errorf("got %d transactions, want 2", n)
+	}
+}
+
+func TestControls(t *testing.T) {
+	o, bus := openOLED(t)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	// want is the first write of each step, a command stream, nil if
+	// nothing is sent.
+	steps := []struct {
+		do   func() error
+		want []byte
+	}{
+		{func() error { return o.SetContrast(0x10) }, []byte{0x00, 0x81, 0x10}},
+		{func() error { return o.Invert(true) }, []byte{0x00, 0xa7}},
+		{func() error { return o.Invert(false) }, []byte{0x00, 0xa6}},
+		// Flipping vertically applies to the RAM content.
+		{func() error { return o.SetFlip(false, true) }, []byte{0x00, 0xc0}},
+		// Flipping horizontally sends the buffer again.
+		{func() error { return o.SetFlip(true, true) }, []byte{0x00, 0xa0}},
+		// The display is already rotated.
+		{func() error { return o.Rotate(true) }, nil},
+		{func() error { return o.Rotate(false) }, []byte{0x00, 0xc8}},
+		{o.Sleep, []byte{0x00, 0xae, 0x8d, 0x10}},
+		{o.Wake, []byte{0x00, 0x8d, 0x14, 0xaf}},
+	}
+	for i, step := range steps {
+		n := len(bus.Writes())
+		if err := step.do(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		w := bus.Writes()[n:]
+		if (len(w) == 0) != (step.want == nil) || (len(w) > 0 && string(w[0]) != string(step.want)) {
+			t.Errorf("step %d: got % x, want % x", i, w, step.want)
+		}
+	}
+	bus.CheckGolden(t, "controls")
+}
@@ -0,0 +1,213 @@
+package monochromeoled
+
+import (
+	"context"
+	"image"
+	"sync"
+	"time"
+)
+
+// DefaultFPS is the frame rate of a Renderer created with a zero fps.
+const DefaultFPS = 30
+
+// RenderStats are the statistics of a Renderer.
+type RenderStats struct {
+	// Frames is the number of frames sent to the display.
+	Frames int
+	// Idle is the number of ticks without a new frame to send.
+	Idle int
+	// Late is the number of frames which took longer than the frame
+	// interval to send, the next ticks being delayed.
+	Late int
+
+	// LastFrameTime and MaxFrameTime are the time taken to send the last
+	// frame and the slowest one.
+	LastFrameTime, MaxFrameTime time.Duration
+	// TotalFrameTime is the time taken to send all the frames.
+	TotalFrameTime time.Duration
+
+	// Bytes is the number of bytes of commands and data sent.
+	Bytes int64
+	// Elapsed is the time since Run was called.
+	Elapsed time.Duration
+}
+
+// AvgFrameTime returns the average time taken to send a frame.
+func (s RenderStats) AvgFrameTime() time.Duration {
+	if s.Frames == 0 {
+		return 0
+	}
+	return s.TotalFrameTime / time.Duration(s.Frames)
+}
+
+// FPS returns the average number of frames sent per second.
+func (s RenderStats) FPS() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Frames) / s.Elapsed.Seconds()
+}
+
+// Throughput returns the average number of bytes sent per second.
+func (s RenderStats) Throughput() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Bytes) / s.Elapsed.Seconds()
+}
+
+// Renderer is a double buffered render loop. The application draws its
+// frames into a back buffer with Frame while Run sends the last complete
+// frame to the display at a steady rate, so that the display never shows
+// a partly drawn frame. Only the parts of the frames which changed are
+// sent, see Draw.
+type Renderer struct {
+	o        *OLED
+	back     *OLED
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending bool // a frame was drawn since the last one was sent
+	stats   RenderStats
+	started time.Time
+}
+
+// NewRenderer returns a render loop sending frames to o at fps frames per
+// second, DefaultFPS if fps is zero. The back buffer starts with the
+// content of o. While Run is running, the application must only draw
+// through Frame.
+func NewRenderer(o *OLED, fps int) *Renderer {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	back := &OLED{
+		Dithering:  o.Dithering,
+		Threshold:  o.Threshold,
+		t:          discard{},
+		controller: o.controller,
+		w:          o.w,
+		h:          o.h,
+		colOffset:  o.colOffset,
+		buf:        make([]byte, len(o.buf)),
+	}
+	copy(back.buf, o.buf)
+	return &Renderer{o: o, back: back, interval: time.Second / time.Duration(fps)}
+}
+
+// Frame calls draw with the back buffer, which holds the previous frame,
+// and queues the result as the next frame to send. The OLED passed to
+// draw must only be used to draw into, during the call.
+func (r *Renderer) Frame(draw func(b *OLED)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	draw(r.back)
+	r.pending = true
+}
+
+// Stats returns the statistics of the render loop.
+func (r *Renderer) Stats() RenderStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.stats
+	if !r.started.IsZero() {
+		s.Elapsed = time.Since(r.started)
+	}
+	return s
+}
+
+// Run sends the frames until the context is done and returns the context
+// error, or the first error reported by the display. Run blocks, it is
+// usually called in its own goroutine.
+func (r *Renderer) Run(ctx context.Context) error {
+	ct := &countingTransport{Transport: r.o.t}
+	r.o.t = ct
+	defer func() { r.o.t = ct.Transport }()
+
+	r.mu.Lock()
+	r.started = time.Now()
+	r.mu.Unlock()
+
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+		if err := r.flush(ct); err != nil {
+			return err
+		}
+	}
+}
+
+// flush sends the pending frame, if any.
+func (r *Renderer) flush(ct *countingTransport) error {
+	r.mu.Lock()
+	pending := r.pending
+	if pending {
+		r.o.load(r.back.buf)
+		r.pending = false
+	} else {
+		r.stats.Idle++
+	}
+	r.mu.Unlock()
+	if !pending {
+		return nil
+	}
+
+	start, sent := time.Now(), ct.n
+	err := r.o.Draw()
+	d := time.Since(start)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats.Bytes += ct.n - sent
+	if err != nil {
+		return err
+	}
+	r.stats.Frames++
+	r.stats.LastFrameTime = d
+	r.stats.TotalFrameTime += d
+	if d > r.stats.MaxFrameTime {
+		r.stats.MaxFrameTime = d
+	}
+	if d > r.interval {
+		r.stats.Late++
+	}
+	return nil
+}
+
+// load copies buf into the buffer, marking the changed bytes as dirty.
+func (o *OLED) load(buf []byte) {
+	for i, v := range buf {
+		if o.buf[i] != v {
+			o.buf[i] = v
+			x, page := i%o.w, i/o.w
+			o.dirty = o.dirty.Union(image.Rect(x, page, x+1, page+1))
+		}
+	}
+}
+
+// countingTransport counts the bytes sent through a transport.
+type countingTransport struct {
+	Transport
+	n int64
+}
+
+func (t *countingTransport) Command(cmd ...byte) error {
+	t.n += int64(len(cmd))
+	return t.Transport.Command(cmd...)
+}
+
+func (t *countingTransport) Data(data []byte) error {
+	t.n += int64(len(data))
+	return t.Transport.Data(data)
+}
+
+// discard is the transport of the back buffers, which are never sent.
+type discard struct{}
+
+func (discard) Command(cmd ...byte) error { return nil }
+func (discard) Data(data []byte) error    { return nil }
+func (discard) Close() error              { return nil }
@@ -0,0 +1,85 @@
+package monochromeoled
+
+import (
+	"context"
+	"image"
+	"testing"
+	"time"
+)
+
+// waitFrames waits until the renderer sent n frames.
+func waitFrames(t *testing.T, r *Renderer, n int) RenderStats {
+	deadline := time.Now().Add(time.Second)
+	for {
+		s := r.Stats()
+		if s.Frames >= n {
+			return s
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d frames, want %d", s.Frames, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRenderer(t *testing.T) {
+	o, bus := openOLED(t)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+
+	r := NewRenderer(o, 1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- r.Run(ctx) }()
+
+	r.Frame(func(b *OLED) {
+		b.SetPixel(3, 10, 1)
+	})
+	s := waitFrames(t, r, 1)
+	// The window command and a single byte of data.
+	if s.Bytes != 8+1 {
+		t.Errorf("sent %d bytes, want 9", s.Bytes)
+	}
+
+	// The back buffer keeps the previous frame.
+	r.Frame(func(b *OLED) {
+		b.FillRect(image.Rect(0, 0, 8, 8), ModeSet)
+	})
+	s = waitFrames(t, r, 2)
+	if s.Bytes != 2*8+1+8 {
+		t.Errorf("sent %d bytes, want %d", s.Bytes, 2*8+1+8)
+	}
+	if o.At(3, 10) != Bit(true) || o.At(7, 7) != Bit(true) {
+		t.Error("the frames were not drawn on the display")
+	}
+	if s.MaxFrameTime < s.LastFrameTime || s.AvgFrameTime() == 0 || s.Elapsed == 0 || s.Throughput() == 0 || s.FPS() == 0 {
+		t.Errorf("inconsistent stats %+v", s)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("got = %v, want = %v", err, context.Canceled)
+	}
+	if _, ok := o.t.(*I2C); !ok {
+		t.Errorf("the transport of the display was not restored, got %T", o.t)
+	}
+	if n := len(bus.Txs()); n != 4 {
+		t.Errorf("got %d transactions, want 4", n)
+	}
+}
+
+func TestRendererError(t *testing.T) {
+	o, bus := openOLED(t)
+	bus.Reset()
+	bus.Fail(1, nil)
+	r := NewRenderer(o, 0)
+	r.Frame(func(b *OLED) {})
+	if err := r.Run(context.Background()); err == nil {
+		t.Error("Run should return the display errors")
+	}
+	if s := r.Stats(); s.Frames != 0 {
+		t.Errorf("got %d frames, want 0", s.Frames)
+	}
+}
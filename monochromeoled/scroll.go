@@ -0,0 +1,126 @@
+package monochromeoled
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ScrollDirection is the horizontal direction of the hardware scrolling.
+type ScrollDirection int
+
+const (
+	// ScrollRight scrolls the content to the right.
+	ScrollRight ScrollDirection = iota
+	// ScrollLeft scrolls the content to the left.
+	ScrollLeft
+)
+
+// scrollFrames maps the number of frames between two scroll steps to
+// their interval setting.
+var scrollFrames = map[int]byte{
+	5:   0x0,
+	64:  0x1,
+	128: 0x2,
+	256: 0x3,
+	3:   0x4,
+	4:   0x5,
+	25:  0x6,
+	2:   0x7,
+}
+
+// Scroll configures the hardware scrolling of the display.
+type Scroll struct {
+	Direction ScrollDirection
+
+	// StartPage and EndPage are the first and last pages scrolled
+	// horizontally, each page being a band of 8 rows.
+	StartPage, EndPage int
+
+	// Frames is the number of frames between two scroll steps, one of 2,
+	// 3, 4, 5, 25, 64, 128 or 256. Zero value is 5.
+	Frames int
+
+	// VerticalOffset is the number of rows the content moves up at each
+	// step. Zero value scrolls horizontally only, any other value
+	// scrolls diagonally.
+	VerticalOffset int
+}
+
+// EnableScroll starts the hardware scrolling configured by s. The
+// content keeps scrolling without any I2C traffic until DisableScroll or
+// Draw is called. The SH1106 doesn't support hardware scrolling.
+func (o *OLED) EnableScroll(s Scroll) error {
+	cmd, err := o.scrollCmd(s)
+	if err != nil {
+		return err
+	}
+	if err := o.DisableScroll(); err != nil {
+		return err
+	}
+	if err := o.t.Command(cmd...); err != nil {
+		return err
+	}
+	if err := o.t.Command(ssd1306_ACTIVATE_SCROLL); err != nil {
+		return err
+	}
+	o.scrolling = true
+	return nil
+}
+
+// DisableScroll stops the hardware scrolling. It does nothing if the
+// display is not scrolling.
+func (o *OLED) DisableScroll() error {
+	if !o.scrolling {
+		return nil
+	}
+	if err := o.t.Command(ssd1306_DEACTIVATE_SCROLL); err != nil {
+		return err
+	}
+	o.scrolling = false
+	return nil
+}
+
+// scrollCmd returns the commands setting up the scrolling s.
+func (o *OLED) scrollCmd(s Scroll) ([]byte, error) {
+	if o.controller == SH1106 {
+		return nil, errors.New("hardware scrolling is not supported by the SH1106")
+	}
+	pages := o.h / 8
+	if s.StartPage < 0 || s.EndPage >= pages || s.StartPage > s.EndPage {
+		return nil, fmt.Errorf("invalid scroll page range %d-%d on this %d pages display", s.StartPage, s.EndPage, pages)
+	}
+	frames := s.Frames
+	if frames == 0 {
+		frames = 5
+	}
+	interval, ok := scrollFrames[frames]
+	if !ok {
+		return nil, fmt.Errorf("invalid scroll interval of %d frames", s.Frames)
+	}
+	if s.VerticalOffset < 0 || s.VerticalOffset >= o.h {
+		return nil, fmt.Errorf("invalid vertical scroll offset %d, want 0-%d", s.VerticalOffset, o.h-1)
+	}
+
+	if s.VerticalOffset == 0 {
+		cmd := byte(ssd1306_RIGHT_HORIZONTAL_SCROLL)
+		if s.Direction == ScrollLeft {
+			cmd = ssd1306_LEFT_HORIZONTAL_SCROLL
+		}
+		return []byte{
+			cmd, 0x00,
+			byte(s.StartPage), interval, byte(s.EndPage),
+			0x00, 0xff,
+		}, nil
+	}
+	cmd := byte(ssd1306_VERTICAL_AND_RIGHT_HORIZONTAL_SCROLL)
+	if s.Direction == ScrollLeft {
+		cmd = ssd1306_VERTICAL_AND_LEFT_HORIZONTAL_SCROLL
+	}
+	return []byte{
+		// the whole display scrolls vertically
+		ssd1306_SET_VERTICAL_SCROLL_AREA, 0, byte(o.h),
+		cmd, 0x00,
+		byte(s.StartPage), interval, byte(s.EndPage),
+		byte(s.VerticalOffset),
+	}, nil
+}
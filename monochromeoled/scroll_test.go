@@ -0,0 +1,68 @@
+package monochromeoled
+
+import "testing"
+
+func TestScroll(t *testing.T) {
+	o, bus := openOLED(t)
+	bus.Reset()
+	if err := o.EnableScroll(Scroll{Direction: ScrollLeft, StartPage: 0, EndPage: 7, Frames: 2}); err != nil {
+		t.Fatal(err)
+	}
+	w := bus.Writes()
+	if want := []byte{0x00, 0x27, 0x00, 0x00, 0x07, 0x07, 0x00, 0xff}; len(w) != 2 || string(w[0]) != string(want) {
+		t.Fatalf("got % x, want the setup command % x", w, want)
+	}
+	if want := []byte{0x00, 0x2f}; string(w[1]) != string(want) {
+		t.Errorf("got activation % x, want % x", w[1], want)
+	}
+	// Changing the scrolling deactivates it first.
+	if err := o.EnableScroll(Scroll{StartPage: 2, EndPage: 3, VerticalOffset: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.SetPixel(0, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	// Draw deactivates the scrolling before writing the RAM.
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "scroll")
+
+	// Disabling an inactive scrolling sends nothing.
+	bus.Reset()
+	if err := o.DisableScroll(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(bus.Writes()); n != 0 {
+		t.Errorf("got %d writes, want none", n)
+	}
+	if err := o.EnableScroll(Scroll{EndPage: 7}); err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := o.DisableScroll(); err != nil {
+		t.Fatal(err)
+	}
+	if w, want := bus.Writes(), []byte{0x00, 0x2e}; len(w) != 1 || string(w[0]) != string(want) {
+		t.Errorf("got % x, want % x", w, want)
+	}
+}
+
+func TestScrollErrors(t *testing.T) {
+	o, bus := openOLED(t)
+	bus.Reset()
+	for _, s := range []Scroll{
+		{StartPage: -1, EndPage: 7},
+		{StartPage: 0, EndPage: 8},
+		{StartPage: 4, EndPage: 3},
+		{EndPage: 7, Frames: 6},
+		{EndPage: 7, VerticalOffset: 64},
+	} {
+		if err := o.EnableScroll(s); err == nil {
+			t.Errorf("EnableScroll(%+v) should fail", s)
+		}
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("nothing should be written on error, got %d transactions", n)
+	}
+}
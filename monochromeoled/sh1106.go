@@ -0,0 +1,64 @@
+package monochromeoled
+
+import "image"
+
+const (
+	// SH1106 registers, the other ones are shared with the SSD1306.
+	sh1106_SET_PAGE      = 0xB0
+	sh1106_SET_COL_LOW   = 0x00
+	sh1106_SET_COL_HIGH  = 0x10
+	sh1106_DCDC          = 0xAD
+	sh1106_DCDC_ON       = 0x8B
+	sh1106_DCDC_OFF      = 0x8A
+	sh1106_PUMP_VOLTAGE  = 0x30
+	sh1106_PUMP_VOLTAGE8 = 0x02 // 8V
+)
+
+// sh1106Panels are the display sizes supported with an SH1106. Its RAM is
+// 132 columns wide, 128 columns displays show the columns 2 to 129.
+var sh1106Panels = map[image.Point]panel{
+	{128, 64}: {comPins: 0x12, colOffset: 2},
+}
+
+// sh1106InitSeq returns the initialization sequence of an SH1106 display
+// h pixels high.
+func sh1106InitSeq(h int, p panel) []byte {
+	return []byte{
+		0xae,
+		0xd5, 0x80,
+		0xa8, byte(h - 1), // multiplex ratio
+		0xd3, 0x00, // set display offset to no offset
+		0x40 | 0,
+		sh1106_DCDC, sh1106_DCDC_ON,
+		sh1106_PUMP_VOLTAGE | sh1106_PUMP_VOLTAGE8,
+
+		0xA0 | 0x1,
+		0xC8,
+		0xda, p.comPins,
+		0x81, 0xcf, // set contrast
+		0xd9, 0x22,
+		0xdb, 0x35,
+		0xa4, 0xa6,
+
+		0xaf,
+	}
+}
+
+// drawPages sends the r area of the buffer, r being in columns and pages,
+// page by page as the SH1106 has no horizontal addressing mode.
+func (o *OLED) drawPages(r image.Rectangle) error {
+	col := o.colOffset + r.Min.X
+	for page := r.Min.Y; page < r.Max.Y; page++ {
+		if err := o.t.Command(
+			sh1106_SET_PAGE|byte(page),
+			sh1106_SET_COL_LOW|byte(col&0xf),
+			sh1106_SET_COL_HIGH|byte(col>>4),
+		); err != nil {
+			return err
+		}
+		if err := o.t.Data(o.buf[page*o.w+r.Min.X : page*o.w+r.Max.X]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package monochromeoled
+
+import (
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func TestSH1106(t *testing.T) {
+	bus := &devicetest.I2C{}
+	o, err := OpenWithOptions(bus, Options{Controller: SH1106})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := len(bus.Writes())
+	// The whole buffer is sent page by page.
+	if err := o.SetPixel(0, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	// Each page starts with the page and column addresses, the panel
+	// being centered in the 132 columns of the controller.
+	w := bus.Writes()[n:]
+	if want := []byte{0x00, 0xb0, 0x02, 0x10}; string(w[0]) != string(want) {
+		t.Errorf("got page address % x, want % x", w[0], want)
+	}
+	if w[1][0] != 0x40 {
+		t.Errorf("got data control byte %#x, want 0x40", w[1][0])
+	}
+	// Then only the changed columns of the changed pages.
+	o.SetPixel(20, 20, 1)
+	o.SetPixel(127, 30, 1)
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	n = len(bus.Writes())
+	if err := o.Sleep(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Wake(); err != nil {
+		t.Fatal(err)
+	}
+	w = bus.Writes()[n:]
+	if want := []byte{0x00, 0xae, 0xad, 0x8a}; len(w) != 2 || string(w[0]) != string(want) {
+		t.Errorf("got sleep % x, want % x", w, want)
+	}
+	if want := []byte{0x00, 0xad, 0x8b, 0xaf}; len(w) == 2 && string(w[1]) != string(want) {
+		t.Errorf("got wake % x, want % x", w[1], want)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "sh1106")
+}
+
+func TestSH1106Errors(t *testing.T) {
+	bus := &devicetest.I2C{}
+	if _, err := OpenWithOptions(bus, Options{Controller: SH1106, Height: 32}); err == nil {
+		t.Error("128x32 SH1106 displays should be rejected")
+	}
+	if _, err := OpenWithOptions(bus, Options{Controller: Controller(2)}); err == nil {
+		t.Error("unknown controllers should be rejected")
+	}
+	o, err := OpenWithOptions(bus, Options{Controller: SH1106})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bus.Reset()
+	if err := o.EnableScroll(Scroll{EndPage: 7}); err == nil {
+		t.Error("EnableScroll should fail on the SH1106")
+	}
+	if n := len(bus.Txs()); n != 0 {
+		t.Errorf("nothing should be written on error, got %d transactions", n)
+	}
+}
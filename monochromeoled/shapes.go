@@ -0,0 +1,316 @@
+package monochromeoled
+
+import (
+	"image"
+	"math"
+)
+
+// Mode is how the drawing primitives change the pixels.
+type Mode int
+
+const (
+	// ModeSet turns the pixels on.
+	ModeSet Mode = iota
+	// ModeClear turns the pixels off.
+	ModeClear
+	// ModeXOR toggles the pixels, drawing twice restores the buffer.
+	ModeXOR
+)
+
+// pen draws pixels in a mode, clipping them to the display.
+type pen struct {
+	o    *OLED
+	m    Mode
+	seen map[image.Point]bool // toggled pixels, see once
+}
+
+func (o *OLED) pen(m Mode) *pen {
+	return &pen{o: o, m: m}
+}
+
+// once makes the pen toggle each pixel once in XOR mode, for the shapes
+// which plot some of their pixels several times.
+func (p *pen) once() *pen {
+	if p.m == ModeXOR {
+		p.seen = make(map[image.Point]bool)
+	}
+	return p
+}
+
+func (p *pen) plot(x, y int) {
+	if x < 0 || y < 0 || x >= p.o.w || y >= p.o.h {
+		return
+	}
+	switch p.m {
+	case ModeSet:
+		p.o.set(x, y, true)
+	case ModeClear:
+		p.o.set(x, y, false)
+	case ModeXOR:
+		if p.seen != nil {
+			pt := image.Pt(x, y)
+			if p.seen[pt] {
+				return
+			}
+			p.seen[pt] = true
+		}
+		p.o.set(x, y, p.o.At(x, y) == Bit(false))
+	}
+}
+
+// hline plots the pixels from x0 to x1 included on row y.
+func (p *pen) hline(x0, x1, y int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y < 0 || y >= p.o.h {
+		return
+	}
+	if x0 < 0 {
+		x0 = 0
+	}
+	if x1 >= p.o.w {
+		x1 = p.o.w - 1
+	}
+	for x := x0; x <= x1; x++ {
+		p.plot(x, y)
+	}
+}
+
+// vline plots the pixels from y0 to y1 included on column x.
+func (p *pen) vline(x, y0, y1 int) {
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	if x < 0 || x >= p.o.w {
+		return
+	}
+	if y0 < 0 {
+		y0 = 0
+	}
+	if y1 >= p.o.h {
+		y1 = p.o.h - 1
+	}
+	for y := y0; y <= y1; y++ {
+		p.plot(x, y)
+	}
+}
+
+// line plots the pixels from x0, y0 to x1, y1 included with the
+// Bresenham algorithm, after clipping the segment to the display.
+func (p *pen) line(x0, y0, x1, y1 int) {
+	x0, y0, x1, y1, ok := p.o.clipLine(x0, y0, x1, y1)
+	if !ok {
+		return
+	}
+	dx, sx := x1-x0, 1
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	dy, sy := y1-y0, 1
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+	err := dx - dy
+	for {
+		p.plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x0 += sx
+		}
+		if e2 < dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// clipLine clips the segment from x0, y0 to x1, y1 to the display with
+// the Liang-Barsky algorithm, keeping its slope. It reports whether a part
+// of the segment is on the display. The segments on the display are
+// returned unchanged.
+func (o *OLED) clipLine(x0, y0, x1, y1 int) (int, int, int, int, bool) {
+	b := o.Bounds()
+	if image.Pt(x0, y0).In(b) && image.Pt(x1, y1).In(b) {
+		return x0, y0, x1, y1, true
+	}
+	fx, fy := float64(x0), float64(y0)
+	dx, dy := float64(x1)-fx, float64(y1)-fy
+	t0, t1 := 0.0, 1.0
+	// each edge of the display, the segment is inside where p*t <= q
+	for _, e := range [...][2]float64{
+		{-dx, fx},
+		{dx, float64(o.w-1) - fx},
+		{-dy, fy},
+		{dy, float64(o.h-1) - fy},
+	} {
+		p, q := e[0], e[1]
+		if p == 0 {
+			if q < 0 {
+				return 0, 0, 0, 0, false
+			}
+			continue
+		}
+		r := q / p
+		if p < 0 {
+			t0 = math.Max(t0, r)
+		} else {
+			t1 = math.Min(t1, r)
+		}
+		if t0 > t1 {
+			return 0, 0, 0, 0, false
+		}
+	}
+	round := func(v float64) int { return int(math.Round(v)) }
+	return round(fx + t0*dx), round(fy + t0*dy), round(fx + t1*dx), round(fy + t1*dy), true
+}
+
+// arcs plots the four quarters of a circle of radius r, centered on the
+// corners of the cx0, cy0, cx1, cy1 rectangle, with the midpoint
+// algorithm. With a zero sized rectangle, it plots a circle.
+func (p *pen) arcs(cx0, cy0, cx1, cy1, r int) {
+	x, y, err := r, 0, 1-r
+	for x >= y {
+		for _, d := range [][2]int{{x, y}, {y, x}} {
+			p.plot(cx1+d[0], cy1+d[1])
+			p.plot(cx0-d[0], cy1+d[1])
+			p.plot(cx1+d[0], cy0-d[1])
+			p.plot(cx0-d[0], cy0-d[1])
+		}
+		y++
+		if err < 0 {
+			err += 2*y + 1
+		} else {
+			x--
+			err += 2*(y-x) + 1
+		}
+	}
+}
+
+// Line draws a line from x0, y0 to x1, y1 included.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) Line(x0, y0, x1, y1 int, m Mode) {
+	o.pen(m).line(x0, y0, x1, y1)
+}
+
+// Polyline draws the lines joining the points in order.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) Polyline(m Mode, pts ...image.Point) {
+	p := o.pen(m).once()
+	for i := 1; i < len(pts); i++ {
+		p.line(pts[i-1].X, pts[i-1].Y, pts[i].X, pts[i].Y)
+	}
+	if len(pts) == 1 {
+		p.plot(pts[0].X, pts[0].Y)
+	}
+}
+
+// Rect draws the outline of r, which covers the pixels from r.Min to
+// r.Max excluded like image.Rectangle.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) Rect(r image.Rectangle, m Mode) {
+	r = r.Canon()
+	if r.Empty() {
+		return
+	}
+	p := o.pen(m).once()
+	p.hline(r.Min.X, r.Max.X-1, r.Min.Y)
+	p.hline(r.Min.X, r.Max.X-1, r.Max.Y-1)
+	p.vline(r.Min.X, r.Min.Y, r.Max.Y-1)
+	p.vline(r.Max.X-1, r.Min.Y, r.Max.Y-1)
+}
+
+// FillRect fills r.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) FillRect(r image.Rectangle, m Mode) {
+	r = r.Canon().Intersect(o.Bounds())
+	p := o.pen(m)
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		p.hline(r.Min.X, r.Max.X-1, y)
+	}
+}
+
+// RoundedRect draws the outline of r with corners rounded with a radius
+// of radius pixels, limited to half of the size of r.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) RoundedRect(r image.Rectangle, radius int, m Mode) {
+	r = r.Canon()
+	if r.Empty() {
+		return
+	}
+	if max := (r.Dx() - 1) / 2; radius > max {
+		radius = max
+	}
+	if max := (r.Dy() - 1) / 2; radius > max {
+		radius = max
+	}
+	if radius < 0 {
+		radius = 0
+	}
+	p := o.pen(m).once()
+	x0, y0, x1, y1 := r.Min.X, r.Min.Y, r.Max.X-1, r.Max.Y-1
+	p.hline(x0+radius, x1-radius, y0)
+	p.hline(x0+radius, x1-radius, y1)
+	p.vline(x0, y0+radius, y1-radius)
+	p.vline(x1, y0+radius, y1-radius)
+	p.arcs(x0+radius, y0+radius, x1-radius, y1-radius, radius)
+}
+
+// Circle draws the outline of the circle centered on cx, cy.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) Circle(cx, cy, radius int, m Mode) {
+	if radius < 0 {
+		return
+	}
+	o.pen(m).once().arcs(cx, cy, cx, cy, radius)
+}
+
+// FloodFill fills the area of pixels with the same value as the pixel at
+// x, y which are connected to it horizontally or vertically. In ModeXOR,
+// the area is inverted.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) FloodFill(x, y int, m Mode) {
+	if !(image.Point{x, y}.In(o.Bounds())) {
+		return
+	}
+	target := o.At(x, y)
+	if (m == ModeSet && target == Bit(true)) || (m == ModeClear && target == Bit(false)) {
+		return
+	}
+	p := o.pen(m)
+	// inside reports whether the pixel is part of the area and not
+	// filled yet, filled pixels having changed.
+	inside := func(x, y int) bool {
+		return x >= 0 && y >= 0 && x < o.w && y < o.h && o.At(x, y) == target
+	}
+	// scanline fill, each span is filled then the rows above and below
+	// are searched for new spans
+	stack := []image.Point{{x, y}}
+	for len(stack) > 0 {
+		pt := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !inside(pt.X, pt.Y) {
+			continue
+		}
+		x0, x1 := pt.X, pt.X
+		for inside(x0-1, pt.Y) {
+			x0--
+		}
+		for inside(x1+1, pt.Y) {
+			x1++
+		}
+		p.hline(x0, x1, pt.Y)
+		for _, ny := range []int{pt.Y - 1, pt.Y + 1} {
+			for nx := x0; nx <= x1; nx++ {
+				// push the first pixel of each span
+				if inside(nx, ny) && (nx == x0 || !inside(nx-1, ny)) {
+					stack = append(stack, image.Pt(nx, ny))
+				}
+			}
+		}
+	}
+}
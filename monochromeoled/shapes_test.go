@@ -0,0 +1,110 @@
+package monochromeoled
+
+import (
+	"image"
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func checkBitmap(t *testing.T, o *OLED, name string) {
+	t.Helper()
+	devicetest.CheckGolden(t, name, art(o, o.Bounds())+"\n")
+}
+
+func TestShapes(t *testing.T) {
+	o, _ := openOLED(t)
+	o.Line(0, 0, 20, 7, ModeSet)
+	o.Line(20, 8, 0, 15, ModeSet)
+	o.Rect(image.Rect(24, 0, 40, 16), ModeSet)
+	o.FillRect(image.Rect(44, 0, 60, 16), ModeSet)
+	o.FillRect(image.Rect(48, 4, 56, 12), ModeClear)
+	o.Circle(72, 8, 7, ModeSet)
+	o.RoundedRect(image.Rect(84, 0, 108, 16), 4, ModeSet)
+	o.Polyline(ModeSet, image.Pt(112, 15), image.Pt(119, 0), image.Pt(126, 15), image.Pt(112, 15))
+	checkBitmap(t, o, "shapes")
+}
+
+func TestShapesClipping(t *testing.T) {
+	o, _ := openOLED(t)
+	o.Circle(0, 63, 10, ModeSet)
+	o.Line(-20, -10, 200, 100, ModeSet)
+	o.FillRect(image.Rect(120, -5, 140, 5), ModeSet)
+	o.RoundedRect(image.Rect(100, 50, 150, 90), 8, ModeSet)
+	checkBitmap(t, o, "clipping")
+}
+
+func TestShapesHugeCoordinates(t *testing.T) {
+	o, _ := openOLED(t)
+	const huge = 1 << 40
+	// Only the part of the shapes on the display is walked.
+	o.Line(-huge, -huge, huge, huge, ModeSet)
+	o.Line(-huge, 10, huge, 10, ModeXOR)
+	o.Rect(image.Rect(-huge, -huge, huge, 20), ModeSet)
+	o.FillRect(image.Rect(-huge, 40, huge, huge), ModeSet)
+	o.Line(huge, huge, huge+1, -huge, ModeSet)
+	for _, pt := range []image.Point{{0, 0}, {30, 30}, {63, 63}, {5, 10}, {70, 19}, {100, 50}} {
+		if o.At(pt.X, pt.Y) != Bit(true) {
+			t.Errorf("pixel %v is off", pt)
+		}
+	}
+	// The XOR line crossed the diagonal.
+	if o.At(10, 10) != Bit(false) {
+		t.Error("pixel (10,10) is on")
+	}
+}
+
+func TestShapesXOR(t *testing.T) {
+	o, _ := openOLED(t)
+	// Drawing twice restores the buffer, the corners are toggled once.
+	for i := 0; i < 2; i++ {
+		o.Rect(image.Rect(1, 1, 10, 10), ModeXOR)
+		o.RoundedRect(image.Rect(20, 1, 30, 10), 20, ModeXOR)
+		o.Circle(40, 5, 4, ModeXOR)
+		o.Polyline(ModeXOR, image.Pt(50, 1), image.Pt(60, 1), image.Pt(55, 9), image.Pt(50, 1))
+		if i == 0 {
+			for _, pt := range []image.Point{{1, 1}, {9, 9}, {50, 1}, {60, 1}} {
+				if o.At(pt.X, pt.Y) != Bit(true) {
+					t.Errorf("pixel %v is off", pt)
+				}
+			}
+		}
+	}
+	if n := countOn(o, o.Bounds()); n != 0 {
+		t.Errorf("got %d pixels on, want 0", n)
+	}
+
+	o.FillRect(image.Rect(0, 0, 8, 8), ModeSet)
+	o.FillRect(image.Rect(4, 4, 12, 12), ModeXOR)
+	if n := countOn(o, o.Bounds()); n != 64+64-2*16 {
+		t.Errorf("got %d pixels on, want %d", n, 64+64-2*16)
+	}
+	o.Line(0, 20, 30, 40, ModeXOR)
+	o.Line(0, 20, 30, 40, ModeXOR)
+	if n := countOn(o, image.Rect(0, 20, 31, 41)); n != 0 {
+		t.Errorf("got %d pixels on, want 0", n)
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	o, _ := openOLED(t)
+	o.Circle(20, 20, 12, ModeSet)
+	o.Rect(image.Rect(14, 14, 27, 27), ModeSet)
+	o.Line(20, 0, 20, 63, ModeSet)
+	// The area between the circle and the square, right of the line.
+	o.FloodFill(30, 20, ModeSet)
+	// Filling with the current value does nothing.
+	o.FloodFill(30, 20, ModeSet)
+	o.FloodFill(60, 60, ModeClear)
+	// The inside of the square, left of the line, is inverted.
+	o.FloodFill(16, 20, ModeXOR)
+	checkBitmap(t, o, "floodfill")
+
+	// Points out of the display are ignored.
+	before := art(o, o.Bounds())
+	o.FloodFill(-1, 0, ModeSet)
+	o.FloodFill(0, 64, ModeSet)
+	if art(o, o.Bounds()) != before {
+		t.Error("filling from out of the display changed the buffer")
+	}
+}
@@ -0,0 +1,218 @@
+package monochromeoled
+
+import (
+	"image"
+	"image/draw"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// Align is the horizontal alignment of text.
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// TextStyle is the style of the text drawn by DrawText and DrawTextBox.
+type TextStyle struct {
+	// Face is the font of the text, such as Font5x7, Font8x16 or any
+	// font.Face from golang.org/x/image/font/opentype. Zero value is
+	// Font5x7. Antialiased glyphs are converted with the display's
+	// Threshold.
+	Face font.Face
+
+	Align Align
+
+	// Inverse draws the text with pixels that are off on a background of
+	// pixels that are on.
+	Inverse bool
+}
+
+func (s TextStyle) face() font.Face {
+	if s.Face == nil {
+		return Font5x7
+	}
+	return s.Face
+}
+
+// DrawText draws text, the top of its first line being at y. Lines are
+// separated by '\n'. x is the left edge, the center or the right edge of
+// the lines depending on the alignment. The parts out of the display are
+// clipped. It returns the bounds of the text on the display.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) DrawText(x, y int, text string, style TextStyle) image.Rectangle {
+	face := style.face()
+	var b image.Rectangle
+	for i, line := range strings.Split(text, "\n") {
+		r := o.drawLine(o, x, y+i*lineHeight(face), line, style)
+		b = b.Union(r)
+	}
+	return b
+}
+
+// DrawTextBox draws text word wrapped to fit in the width of r and
+// aligned within r. The lines which don't fit in the height of r are not
+// drawn and returned in rest, so that they can be drawn on the next page.
+// A call to Draw is required to display it on the OLED display.
+func (o *OLED) DrawTextBox(r image.Rectangle, text string, style TextStyle) (rest string) {
+	face := style.face()
+	x := r.Min.X
+	switch style.Align {
+	case AlignCenter:
+		x = (r.Min.X + r.Max.X) / 2
+	case AlignRight:
+		x = r.Max.X
+	}
+	dst := &clip{o, r.Intersect(o.Bounds())}
+	lh := lineHeight(face)
+	for i, l := range wrap(face, text, r.Dx()) {
+		if (i+1)*lh > r.Dy() {
+			return text[l.start:]
+		}
+		o.drawLine(dst, x, r.Min.Y+i*lh, text[l.start:l.end], style)
+	}
+	return ""
+}
+
+// drawLine draws a single line of text on dst, see DrawText.
+func (o *OLED) drawLine(dst draw.Image, x, y int, line string, style TextStyle) image.Rectangle {
+	face := style.face()
+	w := font.MeasureString(face, line).Ceil()
+	switch style.Align {
+	case AlignCenter:
+		x -= w / 2
+	case AlignRight:
+		x -= w
+	}
+	r := image.Rect(x, y, x+w, y+lineHeight(face))
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.White,
+		Face: face,
+		Dot:  fixed.P(x, y+face.Metrics().Ascent.Ceil()),
+	}
+	if style.Inverse {
+		draw.Draw(dst, r, image.White, image.Point{}, draw.Src)
+		d.Src = image.Black
+	}
+	d.DrawString(line)
+	return r.Intersect(dst.Bounds())
+}
+
+// MeasureText returns the size of text drawn with face by DrawText, the
+// width of its widest line and the height of its lines. A nil face is
+// Font5x7.
+func MeasureText(face font.Face, text string) image.Point {
+	if face == nil {
+		face = Font5x7
+	}
+	lines := strings.Split(text, "\n")
+	var w int
+	for _, line := range lines {
+		if lw := font.MeasureString(face, line).Ceil(); lw > w {
+			w = lw
+		}
+	}
+	return image.Pt(w, len(lines)*lineHeight(face))
+}
+
+// WrapText splits text into lines drawn with face which are at most
+// width pixels wide, as drawn by DrawTextBox. Lines are broken at spaces,
+// words wider than a line are broken anywhere. A nil face is Font5x7.
+func WrapText(face font.Face, text string, width int) []string {
+	if face == nil {
+		face = Font5x7
+	}
+	var lines []string
+	for _, l := range wrap(face, text, width) {
+		lines = append(lines, text[l.start:l.end])
+	}
+	return lines
+}
+
+func lineHeight(face font.Face) int {
+	return face.Metrics().Height.Ceil()
+}
+
+// textLine is a line of wrapped text, from byte start to byte end.
+type textLine struct {
+	start, end int
+}
+
+// wrap splits text into lines at most width pixels wide.
+func wrap(face font.Face, text string, width int) []textLine {
+	var lines []textLine
+	fits := func(start, end int) bool {
+		return font.MeasureString(face, text[start:end]).Ceil() <= width
+	}
+	for start := 0; start <= len(text); {
+		end := strings.IndexByte(text[start:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += start
+		}
+
+		// ls is the start of the current line, le its end so far. The
+		// spaces at the beginning of the first line are kept.
+		ls, le := start, start
+		wrapped := false
+		for i := start; ; {
+			// next word
+			ws := i
+			for ws < end && text[ws] == ' ' {
+				ws++
+			}
+			we := ws
+			for we < end && text[we] != ' ' {
+				we++
+			}
+			if ws == we {
+				break
+			}
+			if le == ls && wrapped {
+				ls, le = ws, ws
+			}
+			switch {
+			case fits(ls, we):
+				le, i = we, we
+			case le > ls:
+				// the word starts a new line
+				lines = append(lines, textLine{ls, le})
+				ls, le, wrapped = ws, ws, true
+			default:
+				// the word is too wide for a line of its own, it is
+				// broken after the last rune that fits, at least one
+				for le < we {
+					_, n := utf8.DecodeRuneInString(text[le:])
+					if le > ls && !fits(ls, le+n) {
+						break
+					}
+					le += n
+				}
+				lines = append(lines, textLine{ls, le})
+				ls, i, wrapped = le, le, true
+			}
+		}
+		// a word broken at its end leaves nothing for another line
+		if !wrapped || le > ls {
+			lines = append(lines, textLine{ls, le})
+		}
+		start = end + 1
+	}
+	return lines
+}
+
+// clip is a draw.Image limited to a part of the display.
+type clip struct {
+	*OLED
+	r image.Rectangle
+}
+
+func (c *clip) Bounds() image.Rectangle { return c.r }
@@ -0,0 +1,168 @@
+package monochromeoled
+
+import (
+	"image"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+// art returns the pixels of r as text art, '#' being on and '.' off.
+func art(o *OLED, r image.Rectangle) string {
+	var lines []string
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		var line []byte
+		for x := r.Min.X; x < r.Max.X; x++ {
+			if o.At(x, y) == Bit(true) {
+				line = append(line, '#')
+			} else {
+				line = append(line, '.')
+			}
+		}
+		lines = append(lines, string(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func checkArt(t *testing.T, o *OLED, r image.Rectangle, want string) {
+	want = strings.Replace(strings.TrimSpace(want), "\t", "", -1)
+	if got := art(o, r); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDrawText(t *testing.T) {
+	o, _ := openOLED(t)
+	b := o.DrawText(1, 1, "Hi!", TextStyle{})
+	if want := image.Rect(1, 1, 19, 9); b != want {
+		t.Errorf("got bounds %v, want %v", b, want)
+	}
+	checkArt(t, o, image.Rect(0, 0, 19, 10), `
+		...................
+		.#...#...#.....#...
+		.#...#.........#...
+		.#...#..##.....#...
+		.#####...#.....#...
+		.#...#...#.....#...
+		.#...#...#.........
+		.#...#..###....#...
+		...................
+		...................
+	`)
+}
+
+func TestDrawTextAlign(t *testing.T) {
+	o, _ := openOLED(t)
+	if b, want := o.DrawText(64, 0, "abc", TextStyle{Align: AlignCenter}), image.Rect(55, 0, 73, 8); b != want {
+		t.Errorf("centered text bounds are %v, want %v", b, want)
+	}
+	if b, want := o.DrawText(128, 8, "abc\nde", TextStyle{Align: AlignRight}), image.Rect(110, 8, 128, 24); b != want {
+		t.Errorf("right aligned text bounds are %v, want %v", b, want)
+	}
+	// The second line is aligned on its own.
+	if n := countOn(o, image.Rect(110, 16, 116, 24)); n != 0 {
+		t.Errorf("got %d pixels on left of the second line", n)
+	}
+	// Text is clipped.
+	if b, want := o.DrawText(120, 60, "abc", TextStyle{}), image.Rect(120, 60, 128, 64); b != want {
+		t.Errorf("clipped text bounds are %v, want %v", b, want)
+	}
+}
+
+func TestDrawTextInverse(t *testing.T) {
+	o, _ := openOLED(t)
+	o.DrawText(0, 0, "-", TextStyle{Inverse: true})
+	checkArt(t, o, image.Rect(0, 0, 7, 8), `
+		######.
+		######.
+		######.
+		.....#.
+		######.
+		######.
+		######.
+		######.
+	`)
+}
+
+func TestDrawTextFaces(t *testing.T) {
+	o, _ := openOLED(t)
+	if b, want := o.DrawText(0, 0, "Go", TextStyle{Face: Font8x16}), image.Rect(0, 0, 16, 16); b != want {
+		t.Errorf("got bounds %v, want %v", b, want)
+	}
+	if n := countOn(o, image.Rect(0, 0, 16, 2)); n != 0 {
+		t.Errorf("got %d pixels on above the 8x16 glyphs", n)
+	}
+	if n := countOn(o, image.Rect(0, 0, 16, 16)); n == 0 {
+		t.Error("no pixel is on")
+	}
+	// Any font.Face is supported.
+	if b, want := o.DrawText(0, 16, "Go", TextStyle{Face: basicfont.Face7x13}), image.Rect(0, 16, 14, 29); b != want {
+		t.Errorf("got bounds %v, want %v", b, want)
+	}
+	// Missing characters are drawn as boxes.
+	o.DrawText(0, 40, "€", TextStyle{})
+	checkArt(t, o, image.Rect(0, 40, 5, 47), `
+		#####
+		#...#
+		#...#
+		#...#
+		#...#
+		#...#
+		#####
+	`)
+}
+
+func TestMeasureText(t *testing.T) {
+	if got, want := MeasureText(nil, "hello\nhi"), image.Pt(30, 16); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := MeasureText(Font8x16, "hello"), image.Pt(40, 16); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		text  string
+		width int
+		want  []string
+	}{
+		{"hello world", 66, []string{"hello world"}},
+		{"hello world", 60, []string{"hello", "world"}},
+		{"hello   big world", 60, []string{"hello", "big world"}},
+		{"  indented text", 60, []string{"  indented", "text"}},
+		{"one\n\ntwo", 60, []string{"one", "", "two"}},
+		{"abcdefghij", 24, []string{"abcd", "efgh", "ij"}},
+		{"a abcdefghij", 24, []string{"a", "abcd", "efgh", "ij"}},
+		{"abcdefgh", 24, []string{"abcd", "efgh"}},
+		{"a b", 5, []string{"a", "b"}},
+		{"trailing ", 60, []string{"trailing"}},
+		{"", 60, []string{""}},
+	}
+	for _, tt := range tests {
+		if got := WrapText(nil, tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WrapText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestDrawTextBox(t *testing.T) {
+	o, _ := openOLED(t)
+	r := image.Rect(10, 10, 40, 26)
+	rest := o.DrawTextBox(r, "one two three four", TextStyle{Align: AlignRight})
+	if rest != "three four" {
+		t.Errorf("got rest %q, want %q", rest, "three four")
+	}
+	if n := countOn(o, o.Bounds()); n != countOn(o, r) {
+		t.Error("pixels were drawn out of the box")
+	}
+	// "two" is right aligned on the second line.
+	if n := countOn(o, image.Rect(10, 18, 22, 26)); n != 0 {
+		t.Errorf("got %d pixels on left of the second line", n)
+	}
+	if n := countOn(o, image.Rect(22, 18, 40, 26)); n == 0 {
+		t.Error("the second line is missing")
+	}
+}
@@ -0,0 +1,158 @@
+package monochromeoled
+
+import (
+	"time"
+
+	"golang.org/x/exp/io/i2c"
+	i2cdriver "golang.org/x/exp/io/i2c/driver"
+	"golang.org/x/exp/io/spi"
+	spidriver "golang.org/x/exp/io/spi/driver"
+)
+
+const (
+	// DefaultSPIMaxSpeed is the SPI clock speed (in Hz) set by OpenSPI.
+	DefaultSPIMaxSpeed = 8000000
+
+	// DefaultSPIMaxTxSize is the default maximum number of bytes sent in
+	// a single SPI transaction, the default buffer size of the Linux
+	// spidev driver.
+	DefaultSPIMaxTxSize = 4096
+)
+
+// Transport sends commands and display RAM data to the controller.
+type Transport interface {
+	// Command sends a command with its parameters.
+	Command(cmd ...byte) error
+	// Data writes data to the display RAM.
+	Data(data []byte) error
+	// Close closes the connection to the controller.
+	Close() error
+}
+
+// Pin is a digital output line, such as a GPIO, driving one of the
+// controller inputs.
+type Pin interface {
+	// Set drives the line high or low.
+	Set(high bool) error
+}
+
+// I2C is the transport of the displays connected on an I2C bus.
+type I2C struct {
+	Device *i2c.Device
+
+	tx []byte // data control byte followed by the data
+}
+
+// OpenI2C opens the display at addr, Addr or AltAddr, on an I2C bus.
+func OpenI2C(o i2cdriver.Opener, addr int) (*I2C, error) {
+	dev, err := i2c.Open(o, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &I2C{Device: dev}, nil
+}
+
+// Command implements Transport.
+func (t *I2C) Command(cmd ...byte) error {
+	return t.Device.Write(append([]byte{0x00}, cmd...)) // command stream
+}
+
+// Data implements Transport.
+func (t *I2C) Data(data []byte) error {
+	t.tx = append(append(t.tx[:0], 0x40), data...) // start frame of pixel data
+	return t.Device.Write(t.tx)
+}
+
+// Close implements Transport.
+func (t *I2C) Close() error {
+	return t.Device.Close()
+}
+
+// SPI is the transport of the displays connected on a 4-wire SPI bus,
+// their D/C input selecting whether commands or data are sent.
+type SPI struct {
+	Device *spi.Device
+
+	// DC is the line connected to the D/C input of the display.
+	DC Pin
+
+	// MaxTxSize is the maximum number of bytes sent in a single SPI
+	// transaction, longer writes are split. Zero means no limit.
+	MaxTxSize int
+}
+
+// OpenSPI opens the display on an SPI bus, dc being connected to its D/C
+// input. The clock speed is set to DefaultSPIMaxSpeed, use
+// Device.SetMaxSpeed to change it.
+func OpenSPI(o spidriver.Opener, dc Pin) (*SPI, error) {
+	dev, err := spi.Open(o)
+	if err != nil {
+		return nil, err
+	}
+	if err := dev.SetMode(spi.Mode0); err != nil {
+		dev.Close()
+		return nil, err
+	}
+	if err := dev.SetBitsPerWord(8); err != nil {
+		dev.Close()
+		return nil, err
+	}
+	if err := dev.SetMaxSpeed(DefaultSPIMaxSpeed); err != nil {
+		dev.Close()
+		return nil, err
+	}
+	return &SPI{Device: dev, DC: dc, MaxTxSize: DefaultSPIMaxTxSize}, nil
+}
+
+// Command implements Transport.
+func (t *SPI) Command(cmd ...byte) error {
+	if err := t.DC.Set(false); err != nil {
+		return err
+	}
+	return t.write(cmd)
+}
+
+// Data implements Transport.
+func (t *SPI) Data(data []byte) error {
+	if err := t.DC.Set(true); err != nil {
+		return err
+	}
+	return t.write(data)
+}
+
+func (t *SPI) write(b []byte) error {
+	for len(b) > 0 {
+		chunk := b
+		if t.MaxTxSize > 0 && len(chunk) > t.MaxTxSize {
+			chunk = chunk[:t.MaxTxSize]
+		}
+		if err := t.Device.Tx(chunk, nil); err != nil {
+			return err
+		}
+		b = b[len(chunk):]
+	}
+	return nil
+}
+
+// Close implements Transport.
+func (t *SPI) Close() error {
+	return t.Device.Close()
+}
+
+// reset pulses the reset line of the display.
+func reset(rst Pin) error {
+	if err := rst.Set(true); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	if err := rst.Set(false); err != nil {
+		return err
+	}
+	// the reset pulse needs to be at least 3µs long
+	time.Sleep(10 * time.Microsecond)
+	if err := rst.Set(true); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	return nil
+}
@@ -0,0 +1,136 @@
+package monochromeoled
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+// fakePin records the level changes of a line, along with the number of
+// transactions sent on bus so far.
+type fakePin struct {
+	name string
+	bus  *devicetest.SPI
+	log  *[]string
+}
+
+func (p fakePin) Set(high bool) error {
+	n := 0
+	if p.bus != nil {
+		n = len(p.bus.Txs())
+	}
+	*p.log = append(*p.log, fmt.Sprintf("%s=%v@%d", p.name, high, n))
+	return nil
+}
+
+// fakeTransport records the commands and data sent to the controller.
+type fakeTransport struct {
+	log    []string
+	fail   error
+	closed bool
+}
+
+func (t *fakeTransport) Command(cmd ...byte) error {
+	t.log = append(t.log, fmt.Sprintf("command % x", cmd))
+	return t.fail
+}
+
+func (t *fakeTransport) Data(data []byte) error {
+	t.log = append(t.log, fmt.Sprintf("data %d bytes", len(data)))
+	return t.fail
+}
+
+func (t *fakeTransport) Close() error {
+	t.closed = true
+	return nil
+}
+
+func TestI2C(t *testing.T) {
+	bus := &devicetest.I2C{}
+	tr, err := OpenI2C(bus, Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Command(0xaf); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Data([]byte{0x01, 0x02}); err != nil {
+		t.Fatal(err)
+	}
+	w := bus.Writes()
+	if want := []byte{0x00, 0xaf}; string(w[0]) != string(want) {
+		t.Errorf("got command % x, want % x", w[0], want)
+	}
+	if want := []byte{0x40, 0x01, 0x02}; string(w[1]) != string(want) {
+		t.Errorf("got data % x, want % x", w[1], want)
+	}
+}
+
+func TestSPI(t *testing.T) {
+	bus := &devicetest.SPI{}
+	var pins []string
+	tr, err := OpenSPI(bus, fakePin{name: "dc", bus: bus, log: &pins})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr.MaxTxSize = 512
+	o, err := New(tr, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := o.SetPixel(0, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "spi")
+
+	// The init sequence and the draw commands are sent with D/C low, the
+	// buffer is sent in two transactions with D/C high.
+	want := "dc=false@0 dc=false@1 dc=true@2"
+	if got := strings.Join(pins, " "); got != want {
+		t.Errorf("got D/C changes %q, want %q", got, want)
+	}
+}
+
+func TestNewReset(t *testing.T) {
+	tr := &fakeTransport{}
+	var pins []string
+	o, err := New(tr, Options{Width: 128, Height: 32, Reset: fakePin{name: "rst", log: &pins}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "rst=true@0 rst=false@0 rst=true@0"; strings.Join(pins, " ") != want {
+		t.Errorf("got reset sequence %q, want %q", strings.Join(pins, " "), want)
+	}
+	if err := o.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"command ae 00 10 d5 40 a8 1f d3 00 40 8d 14 20 00 a1 c8 da 02 81 cf 9d f1 db 40 a4 a6 2e af",
+		"command a4 40 21 00 7f 22 00 03",
+		"data 512 bytes",
+	}
+	if got := strings.Join(tr.log, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("got\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tr := &fakeTransport{fail: devicetest.ErrInjected}
+	if _, err := New(tr, Options{}); err != devicetest.ErrInjected {
+		t.Errorf("got error %v, want %v", err, devicetest.ErrInjected)
+	}
+	if tr.closed {
+		t.Error("New should leave the transport open")
+	}
+	if _, err := New(&fakeTransport{}, Options{Width: 32}); err == nil {
+		t.Error("New should reject unsupported sizes")
+	}
+}
@@ -305,10 +305,10 @@ func (o *OLED96x96) HorizontalScrollProperties(
 
 // EnableScroll enables and starts scrolling
 func (o *OLED96x96) EnableScroll() error {
-	panic("not implemented")
+	return o.sendCmd(activateScrollCmd)
 }
 
 // DisableScroll disables and stops scrolling
 func (o *OLED96x96) DisableScroll() error {
-	panic("not implemented")
+	return o.sendCmd(dectivateScrollCmd)
 }
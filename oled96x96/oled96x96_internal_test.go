@@ -0,0 +1,68 @@
+package oled96x96
+
+import (
+	"testing"
+
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+func openDisplay(t *testing.T) (*OLED96x96, *devicetest.I2C) {
+	bus := &devicetest.I2C{}
+	d, err := Open(bus)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d, bus
+}
+
+func TestOpen(t *testing.T) {
+	d, bus := openDisplay(t)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "open")
+}
+
+func TestOpenError(t *testing.T) {
+	bus := &devicetest.I2C{}
+	bus.Fail(3, nil)
+	if _, err := Open(bus); err == nil {
+		t.Fatal("Open should report the failed initialization step")
+	}
+	if got := len(bus.Txs()); got != 4 {
+		t.Errorf("Open kept going after the failure, %d transactions", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.Write("Hi"); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "write")
+}
+
+func TestContrastLevel(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.ContrastLevel(256); err == nil {
+		t.Error("out of range contrast levels should fail")
+	}
+	if err := d.ContrastLevel(42); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "contrast")
+}
+
+func TestScroll(t *testing.T) {
+	d, bus := openDisplay(t)
+	bus.Reset()
+	if err := d.EnableScroll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DisableScroll(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "scroll")
+}
@@ -1,53 +1,20 @@
 package piglow
 
 import (
-	"bytes"
 	"errors"
 	"reflect"
 	"testing"
 
-	"golang.org/x/exp/io/i2c/driver"
+	"github.com/goiot/devices/internal/devicetest"
 )
 
-type opener struct {
-	buf *bytes.Buffer
-}
-
-func (o opener) Open(addr int, tenbit bool) (driver.Conn, error) {
-	return &conn{buf: o.buf}, nil
-}
-
-type conn struct {
-	buf *bytes.Buffer
-}
-
-func (c conn) Tx(w, r []byte) error {
-	if w != nil {
-		if _, err := c.buf.Write(w); err != nil {
-			return err
-		}
-	}
-	if r != nil {
-		if _, err := c.buf.Read(r); err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
-func (conn) Close() error {
-	return nil
-}
-
-func openPiGlow(t *testing.T) (*PiGlow, *bytes.Buffer) {
-	o := opener{
-		buf: bytes.NewBuffer([]byte{}),
-	}
-	device, err := Open(o)
+func openPiGlow(t *testing.T) (*PiGlow, *devicetest.I2C) {
+	bus := &devicetest.I2C{}
+	device, err := Open(bus)
 	if err != nil {
 		t.Fatal(err)
 	}
-	return device, o.buf
+	return device, bus
 }
 
 func assert(t *testing.T, want, got interface{}) {
@@ -57,7 +24,7 @@ func assert(t *testing.T, want, got interface{}) {
 }
 
 func TestGreen(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Green(1); err != nil {
 		t.Fatal(err)
 	}
@@ -67,11 +34,11 @@ func TestGreen(t *testing.T) {
 		0x0E, 0x01,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestBlue(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Blue(1); err != nil {
 		t.Fatal(err)
 	}
@@ -81,11 +48,11 @@ func TestBlue(t *testing.T) {
 		0x0F, 0x01,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestYellow(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Yellow(1); err != nil {
 		t.Fatal(err)
 	}
@@ -95,11 +62,11 @@ func TestYellow(t *testing.T) {
 		0x010, 0x01,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestOrange(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Orange(1); err != nil {
 		t.Fatal(err)
 	}
@@ -109,11 +76,11 @@ func TestOrange(t *testing.T) {
 		0x011, 0x01,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestWhite(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.White(1); err != nil {
 		t.Fatal(err)
 	}
@@ -123,11 +90,11 @@ func TestWhite(t *testing.T) {
 		0x0D, 0x01,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestRed(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Red(1); err != nil {
 		t.Fatal(err)
 	}
@@ -137,11 +104,11 @@ func TestRed(t *testing.T) {
 		0x012, 0x01,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestSetBrightness(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.SetBrightness(10); err != nil {
 		t.Fatal(err)
 	}
@@ -166,11 +133,11 @@ func TestSetBrightness(t *testing.T) {
 		0x012, 0x0A,
 		0x16, 0xFF,
 	}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestSetLEDBrightness(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 
 	var states = []struct {
 		led   int
@@ -198,43 +165,43 @@ func TestSetLEDBrightness(t *testing.T) {
 	}
 
 	for _, state := range states {
-		buf.Reset()
+		bus.Reset()
 		if err := device.SetLEDBrightness(state.led, state.level); err != nil {
 			t.Log(err)
 		}
-		assert(t, state.want, buf.Bytes())
+		assert(t, state.want, bus.Written())
 	}
 }
 
 func TestReset(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Reset(); err != nil {
 		t.Fatal(err)
 	}
 	want := []byte{0x17, 0xff}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestShutdown(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Shutdown(); err != nil {
 		t.Fatal(err)
 	}
 	want := []byte{0x00, 0x00}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestEnable(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Enable(); err != nil {
 		t.Fatal(err)
 	}
 	want := []byte{0x00, 0x01}
-	assert(t, want, buf.Bytes())
+	assert(t, want, bus.Written())
 }
 
 func TestSetLEDControlRegister(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 
 	var states = []struct {
 		register int
@@ -249,16 +216,16 @@ func TestSetLEDControlRegister(t *testing.T) {
 	}
 
 	for _, state := range states {
-		buf.Reset()
+		bus.Reset()
 
 		err := device.SetLEDControlRegister(state.register, state.enables)
-		assert(t, state.want, buf.Bytes())
+		assert(t, state.want, bus.Written())
 		assert(t, state.err, err)
 	}
 }
 
 func TestSetup(t *testing.T) {
-	device, buf := openPiGlow(t)
+	device, bus := openPiGlow(t)
 	if err := device.Setup(); err != nil {
 		t.Fatal(err)
 	}
@@ -272,5 +239,24 @@ func TestSetup(t *testing.T) {
 		0x15, 0xFF,
 		0x16, 0xFF,
 	}
-	assert(t, got, buf.Bytes())
+	assert(t, got, bus.Written())
+}
+
+func TestOpenClose(t *testing.T) {
+	device, bus := openPiGlow(t)
+	if err := device.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "i2c 0x54 open\ni2c 0x54 close\n"
+	assert(t, want, bus.Transcript())
+}
+
+func TestWriteError(t *testing.T) {
+	device, bus := openPiGlow(t)
+	bus.Fail(1, nil)
+	if err := device.Green(1); err != devicetest.ErrInjected {
+		t.Fatalf("got = %v, want = %v", err, devicetest.ErrInjected)
+	}
+	// Green stops at the first failure.
+	assert(t, 2, len(bus.Txs()))
 }
@@ -0,0 +1,119 @@
+// Package ws2801 implements a driver for the WS2801 LED strips.
+//
+// The strips share the dotstar strip model: colors are dotstar.RGBA
+// values and LEDs implements dotstar.Strip.
+package ws2801
+
+import (
+	"fmt"
+
+	"github.com/goiot/devices/dotstar"
+	"github.com/goiot/devices/internal/ledstrip"
+	"golang.org/x/exp/io/spi"
+	"golang.org/x/exp/io/spi/driver"
+)
+
+const (
+	// DefaultMaxSpeed is the SPI clock speed (in Hz) set by Open.
+	DefaultMaxSpeed = 1000000
+
+	// DefaultMaxTxSize is the default maximum number of bytes sent in
+	// a single SPI transaction.
+	DefaultMaxTxSize = dotstar.DefaultMaxTxSize
+)
+
+// LEDs represent a strip of WS2801 LEDs.
+type LEDs struct {
+	// Device is the underlying SPI bus that is used to communicate the
+	// LED strip. Most users don't have to access this field.
+	Device *spi.Device
+
+	// MaxTxSize is the maximum number of bytes sent in a single SPI
+	// transaction. Frames can't be split since WS2801 LEDs latch their
+	// color once the clock stays low for 500µs, Draw fails if the frame
+	// is longer. Raise the bufsiz parameter of the spidev kernel module
+	// to drive long strips. Zero means no limit.
+	MaxTxSize int
+
+	vals []dotstar.RGBA
+	tx   []byte // frame buffer reused by Draw
+}
+
+// Open opens a new LED strip with n WS2801 LEDs. The SPI clock is set
+// to DefaultMaxSpeed, use SetMaxSpeed to change it. An LED strip
+// must be closed if no longer in use.
+func Open(o driver.Opener, n int) (*LEDs, error) {
+	dev, err := spi.Open(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dev.SetMode(spi.Mode0); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	if err := dev.SetBitsPerWord(8); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	if err := dev.SetMaxSpeed(DefaultMaxSpeed); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	return &LEDs{
+		Device:    dev,
+		MaxTxSize: DefaultMaxTxSize,
+		vals:      make([]dotstar.RGBA, n),
+	}, nil
+}
+
+// SetMaxSpeed sets the SPI clock speed in Hz. WS2801 LEDs support
+// clocks up to 25MHz.
+func (d *LEDs) SetMaxSpeed(hz int) error {
+	return d.Device.SetMaxSpeed(hz)
+}
+
+// Len returns the number of LEDs on the strip.
+func (d *LEDs) Len() int {
+	return len(d.vals)
+}
+
+// SetRGBA sets the ith LED's color to the given RGBA value.
+// WS2801 LEDs have no global brightness, the color is scaled by A
+// which ranges from 0 (off) to 31 (full brightness) like on dotstar
+// LEDs. A call to Draw is required to transmit the new value
+// to the LED strip.
+func (d *LEDs) SetRGBA(i int, v dotstar.RGBA) {
+	d.vals[i] = v
+}
+
+// Draw displays the RGBA values set on the actual LED strip.
+// Each LED gets 24 bits of raw red, green and blue values sent in a
+// single transaction, the strip latches the colors once the clock stays
+// idle.
+func (d *LEDs) Draw() error {
+	n := len(d.vals)
+	if d.MaxTxSize > 0 && 3*n > d.MaxTxSize {
+		return fmt.Errorf("frame is %d bytes long, the limit is %d bytes", 3*n, d.MaxTxSize)
+	}
+	if len(d.tx) != 3*n {
+		d.tx = make([]byte, 3*n)
+	}
+	for i, c := range d.vals {
+		j := i * 3
+		d.tx[j] = ledstrip.Scale(c.R, c.A)
+		d.tx[j+1] = ledstrip.Scale(c.G, c.A)
+		d.tx[j+2] = ledstrip.Scale(c.B, c.A)
+	}
+
+	return d.Device.Tx(d.tx, nil)
+}
+
+// Close frees the underlying resources. It must be called once
+// the LED strip is no longer in use.
+func (d *LEDs) Close() error {
+	return d.Device.Close()
+}
@@ -0,0 +1,62 @@
+package ws2801
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goiot/devices/dotstar"
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+var _ dotstar.Strip = (*LEDs)(nil)
+
+func openLEDs(t *testing.T, bus *devicetest.SPI, n int) *LEDs {
+	d, err := Open(bus, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDraw(t *testing.T) {
+	bus := &devicetest.SPI{}
+	d := openLEDs(t, bus, 3)
+	d.SetRGBA(0, dotstar.RGBA{R: 255, G: 128, B: 1, A: 31})
+	d.SetRGBA(1, dotstar.RGBA{R: 62, G: 31, B: 255, A: 0})
+	d.SetRGBA(2, dotstar.RGBA{R: 62, G: 31, B: 255, A: 16})
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "draw")
+
+	want := []byte{
+		255, 128, 1,
+		0, 0, 0,
+		32, 16, 131,
+	}
+	if got := bus.Writes()[0]; !bytes.Equal(got, want) {
+		t.Errorf("got = %v, want = %v", got, want)
+	}
+}
+
+func TestDrawTooLong(t *testing.T) {
+	bus := &devicetest.SPI{}
+	d := openLEDs(t, bus, 3)
+	d.MaxTxSize = 8
+	if err := d.Draw(); err == nil {
+		t.Fatal("frames longer than MaxTxSize can't be split and must be rejected")
+	}
+	if len(bus.Writes()) != 0 {
+		t.Error("nothing should be sent when the frame is too long")
+	}
+	d.MaxTxSize = 0
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(bus.Writes()); got != 1 {
+		t.Errorf("got %d transactions, want 1", got)
+	}
+}
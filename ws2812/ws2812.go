@@ -0,0 +1,187 @@
+// Package ws2812 implements a driver for the WS2812B and SK6812 LED
+// strips, also known as NeoPixels, driven from the MOSI line of an SPI bus.
+//
+// NeoPixels have no clock line, bits are encoded by the length of high
+// pulses. The driver runs the SPI clock at SPISpeed and encodes each
+// NeoPixel bit as three SPI bits: 100 for a 0 and 110 for a 1, giving high
+// pulses of 417ns and 833ns.
+//
+// The strips share the dotstar strip model: colors are dotstar.RGBA
+// values and LEDs implements dotstar.Strip.
+package ws2812
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goiot/devices/dotstar"
+	"github.com/goiot/devices/internal/ledstrip"
+	"golang.org/x/exp/io/spi"
+	"golang.org/x/exp/io/spi/driver"
+)
+
+const (
+	// SPISpeed is the SPI clock speed (in Hz) used to encode the bits.
+	SPISpeed = 2400000
+
+	// DefaultResetTime is the default time the data line is kept low
+	// after a frame to latch the colors. It covers the 280µs required by
+	// recent WS2812B revisions and the 80µs of SK6812 LEDs.
+	DefaultResetTime = 300 * time.Microsecond
+
+	// DefaultMaxTxSize is the default maximum number of bytes sent in
+	// a single SPI transaction.
+	DefaultMaxTxSize = dotstar.DefaultMaxTxSize
+)
+
+// RGBW represents the color of an RGBW LED such as the SK6812 RGBW.
+// The white component is ignored by RGB LEDs.
+type RGBW struct {
+	R byte // R represents the red intensity.
+	G byte // G represents the green intensity.
+	B byte // B represents the blue intensity.
+	W byte // W represents the white intensity.
+}
+
+// LEDs represent a strip of NeoPixel LEDs.
+type LEDs struct {
+	// Device is the underlying SPI bus that is used to communicate the
+	// LED strip. Most users don't have to access this field.
+	Device *spi.Device
+
+	// ResetTime is the time the data line is kept low after a frame.
+	ResetTime time.Duration
+
+	// MaxTxSize is the maximum number of bytes sent in a single SPI
+	// transaction. Frames can't be split since a pause longer than a few
+	// microseconds latches the colors, Draw fails if the encoded frame is
+	// longer. Raise the bufsiz parameter of the spidev kernel module to
+	// drive long strips. Zero means no limit.
+	MaxTxSize int
+
+	rgbw bool
+	vals []RGBW
+	tx   []byte // frame buffer reused by Draw
+}
+
+// Open opens a new LED strip with n RGB NeoPixels such as the WS2812B
+// or the SK6812. An LED strip must be closed if no longer in use.
+func Open(o driver.Opener, n int) (*LEDs, error) {
+	return open(o, n, false)
+}
+
+// OpenRGBW opens a new LED strip with n RGBW NeoPixels such as the
+// SK6812 RGBW. An LED strip must be closed if no longer in use.
+func OpenRGBW(o driver.Opener, n int) (*LEDs, error) {
+	return open(o, n, true)
+}
+
+func open(o driver.Opener, n int, rgbw bool) (*LEDs, error) {
+	dev, err := spi.Open(o)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dev.SetMode(spi.Mode0); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	if err := dev.SetBitsPerWord(8); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	if err := dev.SetMaxSpeed(SPISpeed); err != nil {
+		dev.Close()
+		return nil, err
+	}
+
+	return &LEDs{
+		Device:    dev,
+		ResetTime: DefaultResetTime,
+		MaxTxSize: DefaultMaxTxSize,
+		rgbw:      rgbw,
+		vals:      make([]RGBW, n),
+	}, nil
+}
+
+// Len returns the number of LEDs on the strip.
+func (d *LEDs) Len() int {
+	return len(d.vals)
+}
+
+// SetRGBA sets the ith LED's color to the given RGBA value, turning off
+// the white component of RGBW LEDs. NeoPixels have no global brightness,
+// the color is scaled by A which ranges from 0 (off) to 31 (full
+// brightness) like on dotstar LEDs. A call to Draw is required to
+// transmit the new value to the LED strip.
+func (d *LEDs) SetRGBA(i int, v dotstar.RGBA) {
+	d.vals[i] = RGBW{
+		R: ledstrip.Scale(v.R, v.A),
+		G: ledstrip.Scale(v.G, v.A),
+		B: ledstrip.Scale(v.B, v.A),
+	}
+}
+
+// SetRGBW sets the ith LED's color to the given RGBW value.
+// A call to Draw is required to transmit the new value to the LED strip.
+func (d *LEDs) SetRGBW(i int, v RGBW) {
+	d.vals[i] = v
+}
+
+// Draw displays the colors set on the actual LED strip. The colors are
+// sent in GRB (or GRBW) order followed by the reset time.
+func (d *LEDs) Draw() error {
+	bpp := 3
+	if d.rgbw {
+		bpp = 4
+	}
+	reset := int((d.ResetTime*SPISpeed/time.Second + 7) / 8)
+	size := len(d.vals)*bpp*3 + reset
+	if d.MaxTxSize > 0 && size > d.MaxTxSize {
+		return fmt.Errorf("frame is %d bytes long, the limit is %d bytes", size, d.MaxTxSize)
+	}
+	if len(d.tx) != size {
+		d.tx = make([]byte, size)
+	}
+
+	tx := d.tx
+	for _, c := range d.vals {
+		tx = encode(tx, c.G)
+		tx = encode(tx, c.R)
+		tx = encode(tx, c.B)
+		if d.rgbw {
+			tx = encode(tx, c.W)
+		}
+	}
+	// keep the line low to latch the colors
+	for i := range tx {
+		tx[i] = 0x00
+	}
+	return d.Device.Tx(d.tx, nil)
+}
+
+// Close frees the underlying resources. It must be called once
+// the LED strip is no longer in use.
+func (d *LEDs) Close() error {
+	return d.Device.Close()
+}
+
+// encode writes the 3 SPI bytes encoding v at the beginning of tx and
+// returns the rest of tx.
+func encode(tx []byte, v byte) []byte {
+	var bits uint32
+	for i := uint(0); i < 8; i++ {
+		bits <<= 3
+		if v&(0x80>>i) != 0 {
+			bits |= 6 // 110
+		} else {
+			bits |= 4 // 100
+		}
+	}
+	tx[0] = byte(bits >> 16)
+	tx[1] = byte(bits >> 8)
+	tx[2] = byte(bits)
+	return tx[3:]
+}
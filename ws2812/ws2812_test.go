@@ -0,0 +1,106 @@
+package ws2812
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/goiot/devices/dotstar"
+	"github.com/goiot/devices/internal/devicetest"
+)
+
+var _ dotstar.Strip = (*LEDs)(nil)
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		v    byte
+		want []byte
+	}{
+		{0x00, []byte{0x92, 0x49, 0x24}}, // 100 100 100 100 100 100 100 100
+		{0xff, []byte{0xdb, 0x6d, 0xb6}}, // 110 110 110 110 110 110 110 110
+		{0x80, []byte{0xd2, 0x49, 0x24}}, // 110 100 100 100 100 100 100 100
+		{0x01, []byte{0x92, 0x49, 0x26}}, // 100 100 100 100 100 100 100 110
+		{0xa5, []byte{0xd3, 0x49, 0xa6}}, // 110 100 110 100 100 110 100 110
+	}
+	for _, tt := range tests {
+		got := make([]byte, 3)
+		encode(got, tt.v)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("encode(%#02x) = %x, want = %x", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestDrawRGB(t *testing.T) {
+	bus := &devicetest.SPI{}
+	d, err := Open(bus, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.SetRGBA(0, dotstar.RGBA{R: 0xff, G: 0x00, B: 0x80, A: 31})
+	d.SetRGBA(1, dotstar.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0})
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bus.CheckGolden(t, "rgb")
+
+	want := []byte{
+		0x92, 0x49, 0x24, // G
+		0xdb, 0x6d, 0xb6, // R
+		0xd2, 0x49, 0x24, // B
+		0x92, 0x49, 0x24,
+		0x92, 0x49, 0x24,
+		0x92, 0x49, 0x24,
+	}
+	// 300µs at 2.4MHz is 720 bits of reset.
+	want = append(want, make([]byte, 90)...)
+	if got := bus.Writes()[0]; !bytes.Equal(got, want) {
+		t.Errorf("got = %x, want = %x", got, want)
+	}
+}
+
+func TestDrawRGBW(t *testing.T) {
+	bus := &devicetest.SPI{}
+	d, err := OpenRGBW(bus, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.ResetTime = 80 * time.Microsecond
+	d.SetRGBW(0, RGBW{R: 0x01, G: 0xa5, B: 0x00, W: 0xff})
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []byte{
+		0xd3, 0x49, 0xa6, // G
+		0x92, 0x49, 0x26, // R
+		0x92, 0x49, 0x24, // B
+		0xdb, 0x6d, 0xb6, // W
+	}
+	// 80µs at 2.4MHz is 192 bits of reset.
+	want = append(want, make([]byte, 24)...)
+	if got := bus.Writes()[0]; !bytes.Equal(got, want) {
+		t.Errorf("got = %x, want = %x", got, want)
+	}
+}
+
+func TestDrawTooLong(t *testing.T) {
+	bus := &devicetest.SPI{}
+	d, err := Open(bus, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Draw(); err == nil {
+		t.Fatal("frames longer than MaxTxSize can't be split and must be rejected")
+	}
+	if len(bus.Writes()) != 0 {
+		t.Error("nothing should be sent when the frame is too long")
+	}
+	d.MaxTxSize = 0
+	if err := d.Draw(); err != nil {
+		t.Fatal(err)
+	}
+}